@@ -0,0 +1,252 @@
+package clinics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// minutesPerDay is the length of a day, times are stored as minutes since midnight
+const minutesPerDay = 24 * 60
+
+// Modes of matching the openFrom and openTo search conditions against the opening hours of clinics
+const (
+	timeMatchWithin       = "within"
+	timeMatchContains     = "contains"
+	timeMatchOverlaps     = "overlaps"
+	timeMatchStartsBefore = "startsBefore"
+	timeMatchEndsAfter    = "endsAfter"
+)
+
+// timings is a single opening interval as it appears in the clinic lists
+type timings struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+/* openingHours is the list of intervals a clinic is open during the day. In the clinic lists it is
+either a single {"from", "to"} object or a list of them. An interval whose "to" is not after its
+"from", e.g. 22:00 to 06:00, wraps past midnight.*/
+type openingHours []timings
+
+// timeInterval is an opening interval in minutes, end is past minutesPerDay when it wraps midnight
+type timeInterval struct {
+	start int
+	end   int
+}
+
+func (hours *openingHours) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var intervals []timings
+		if err := json.Unmarshal(data, &intervals); err != nil {
+			return err
+		}
+		*hours = intervals
+		return nil
+	}
+
+	var interval timings
+	if err := json.Unmarshal(data, &interval); err != nil {
+		return err
+	}
+	*hours = openingHours{interval}
+	return nil
+}
+
+// MarshalJSON keeps a single interval as an object, like in the clinic lists
+func (hours openingHours) MarshalJSON() ([]byte, error) {
+	if len(hours) == 1 {
+		return json.Marshal(hours[0])
+	}
+	return json.Marshal([]timings(hours))
+}
+
+/* [parseClockTime] - Convert a "15:04" time into minutes since midnight. "24:00" is accepted as
+the end of the day.*/
+
+func parseClockTime(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hours, hourErr := strconv.Atoi(parts[0])
+	minutes, minuteErr := strconv.Atoi(parts[1])
+	if hourErr != nil || minuteErr != nil || hours < 0 || hours > 24 || minutes < 0 || minutes > 59 ||
+		(hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+/* [newTimeInterval] - Convert a from/to pair of minutes into an interval, wrapping it past
+midnight when to is not after from. Equal times are open all day.*/
+
+func newTimeInterval(from int, to int) timeInterval {
+	if to <= from {
+		to += minutesPerDay
+	}
+	return timeInterval{start: from, end: to}
+}
+
+func (t timings) interval() (timeInterval, error) {
+	from, err := parseClockTime(t.From)
+	if err != nil {
+		return timeInterval{}, err
+	}
+	to, err := parseClockTime(t.To)
+	if err != nil {
+		return timeInterval{}, err
+	}
+	if from == minutesPerDay {
+		return timeInterval{}, errors.New("opening time can not be 24:00")
+	}
+	return newTimeInterval(from, to), nil
+}
+
+/* [validateOpeningHours] - Common function called while parsing the clinic lists to convert the
+opening hours of a clinic into intervals. Invalid intervals are logged and left out, so they never
+match an availability filter.*/
+
+func validateOpeningHours(clinicType string, clinicName string, hours openingHours) []timeInterval {
+	intervals := make([]timeInterval, 0, len(hours))
+	for _, hour := range hours {
+		interval, err := hour.interval()
+		if err != nil {
+			log.Printf("Ignoring opening hours %q-%q of %s clinic %q: %v", hour.From, hour.To, clinicType, clinicName, err)
+			continue
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals
+}
+
+/* [validateTimeMatchParams] - Validate the timeMatch query param. Opening hours are matched with
+the legacy within mode unless another mode is asked for, which needs the time it compares to.*/
+
+func validateTimeMatchParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	searchConditionKeys.timeMatchMode = timeMatchWithin
+
+	keys, ok := queryParams["timeMatch"]
+	if !ok {
+		return nil
+	}
+	switch keys[0] {
+	case timeMatchWithin, timeMatchContains, timeMatchOverlaps:
+		if !searchConditionKeys.hasTimeSearchKeys() {
+			return errors.New("Please provide open from or open to for timeMatch.")
+		}
+	case timeMatchStartsBefore:
+		if len(searchConditionKeys.openFrom) == 0 {
+			return errors.New("Please provide open from for timeMatch=startsBefore.")
+		}
+	case timeMatchEndsAfter:
+		if len(searchConditionKeys.openTo) == 0 {
+			return errors.New("Please provide open to for timeMatch=endsAfter.")
+		}
+	default:
+		return errors.New("Please provide a valid value for timeMatch, one of within, contains, overlaps, startsBefore, endsAfter.")
+	}
+	searchConditionKeys.timeMatchMode = keys[0]
+	return nil
+}
+
+/* [matchAvailability] - Common function to check the opening hours of a clinic against the openFrom
+and openTo search conditions. The clinic matches if any of its intervals does for any of the
+requested openFrom and openTo values. The requested
+window runs from openFrom to openTo and may wrap midnight, a single bound is a point in time.
+	a) within - the interval lies within the window; with openFrom only it starts at or after
+	   openFrom, with openTo only it ends at or before openTo and must not wrap midnight
+	b) contains - the window lies within the interval, so the clinic is open the whole time; with a
+	   single bound the clinic is open at openFrom or until openTo
+	c) overlaps - the interval and the window share some time; with openFrom only the clinic is
+	   still open after openFrom, with openTo only it opens before openTo
+	d) startsBefore - the interval starts at or before openFrom
+	e) endsAfter - the interval ends at or after openTo, or the next day when the window wraps*/
+
+func matchAvailability(intervals []timeInterval, searchConditionKeys searchConditions) bool {
+	hasFrom, hasTo := len(searchConditionKeys.openFrom) > 0, len(searchConditionKeys.openTo) > 0
+	// a missing bound is tried once with a value that is never used
+	froms, tos := searchConditionKeys.openFrom, searchConditionKeys.openTo
+	if !hasFrom {
+		froms = []int{0}
+	}
+	if !hasTo {
+		tos = []int{0}
+	}
+
+	for _, interval := range intervals {
+		for _, from := range froms {
+			for _, to := range tos {
+				if matchInterval(interval, searchConditionKeys.timeMatchMode, hasFrom, hasTo, from, to) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func matchInterval(interval timeInterval, timeMatchMode string, hasFrom bool, hasTo bool, from int, to int) bool {
+	switch timeMatchMode {
+	case timeMatchContains:
+		switch {
+		case hasFrom && hasTo:
+			return interval.contains(newTimeInterval(from, to))
+		case hasFrom:
+			return interval.contains(timeInterval{start: from, end: from + 1})
+		default:
+			return interval.contains(timeInterval{start: to - 1, end: to})
+		}
+	case timeMatchOverlaps:
+		switch {
+		case hasFrom && hasTo:
+			return interval.overlaps(newTimeInterval(from, to))
+		case hasFrom:
+			return interval.end > from
+		default:
+			return interval.start < to
+		}
+	case timeMatchStartsBefore:
+		return interval.start <= from
+	case timeMatchEndsAfter:
+		if hasFrom {
+			return interval.end >= newTimeInterval(from, to).end
+		}
+		return interval.end >= to
+	}
+
+	switch {
+	case hasFrom && hasTo:
+		return newTimeInterval(from, to).contains(interval)
+	case hasFrom:
+		return interval.start >= from
+	default:
+		return interval.end <= to
+	}
+}
+
+// contains checks if other lies within the interval, on the same day or the next
+func (interval timeInterval) contains(other timeInterval) bool {
+	for _, shift := range []int{0, minutesPerDay} {
+		if interval.start <= other.start+shift && other.end+shift <= interval.end {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps checks if other shares some time with the interval, on the day before, the same day or the next
+func (interval timeInterval) overlaps(other timeInterval) bool {
+	for _, shift := range []int{-minutesPerDay, 0, minutesPerDay} {
+		if interval.start < other.end+shift && other.start+shift < interval.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package clinics
+
+import "testing"
+
+func TestMatchAvailability(t *testing.T) {
+	day := []timeInterval{newTimeInterval(9*60, 17*60)}
+	overnight := []timeInterval{newTimeInterval(22*60, 6*60)}
+
+	tests := []struct {
+		name              string
+		timeMatchMode     string
+		openFrom          []string
+		openTo            []string
+		expectedDay       bool
+		expectedOvernight bool
+	}{
+		{"within a day window", timeMatchWithin, []string{"08:00"}, []string{"18:00"}, true, false},
+		{"within an overnight window", timeMatchWithin, []string{"21:00"}, []string{"07:00"}, false, true},
+		{"within open from only", timeMatchWithin, []string{"09:00"}, nil, true, true},
+		{"within open to only", timeMatchWithin, nil, []string{"07:00"}, false, false},
+		{"within open to only late", timeMatchWithin, nil, []string{"18:00"}, true, false},
+		{"within any of the open from values", timeMatchWithin, []string{"07:00", "10:00"}, []string{"18:00"}, true, false},
+		{"contains a day window", timeMatchContains, []string{"10:00"}, []string{"12:00"}, true, false},
+		{"contains an overnight window", timeMatchContains, []string{"23:00"}, []string{"02:00"}, false, true},
+		{"contains open from only", timeMatchContains, []string{"03:00"}, nil, false, true},
+		{"overlaps the evening", timeMatchOverlaps, []string{"16:00"}, []string{"23:00"}, true, true},
+		{"overlaps nothing", timeMatchOverlaps, []string{"18:00"}, []string{"21:00"}, false, false},
+		{"overlaps open from only", timeMatchOverlaps, []string{"05:00"}, nil, true, true},
+		{"starts before", timeMatchStartsBefore, []string{"09:00"}, nil, true, false},
+		{"ends after", timeMatchEndsAfter, nil, []string{"20:00"}, false, true},
+		{"ends after an overnight window", timeMatchEndsAfter, []string{"21:00"}, []string{"05:00"}, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			searchConditionKeys := searchConditions{
+				openFrom:      clockTimes(t, test.openFrom),
+				openTo:        clockTimes(t, test.openTo),
+				timeMatchMode: test.timeMatchMode,
+			}
+
+			if match := matchAvailability(day, searchConditionKeys); match != test.expectedDay {
+				t.Errorf("matchAvailability(09:00-17:00) = %v, want %v", match, test.expectedDay)
+			}
+			if match := matchAvailability(overnight, searchConditionKeys); match != test.expectedOvernight {
+				t.Errorf("matchAvailability(22:00-06:00) = %v, want %v", match, test.expectedOvernight)
+			}
+		})
+	}
+}
+
+func TestParseClockTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		valid    bool
+	}{
+		{"00:00", 0, true},
+		{"09:30", 570, true},
+		{"24:00", minutesPerDay, true},
+		{"24:30", 0, false},
+		{"9:30", 0, false},
+		{"12:60", 0, false},
+		{"noon", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			minutes, err := parseClockTime(test.value)
+			if (err == nil) != test.valid {
+				t.Fatalf("parseClockTime(%q) error = %v, want valid %v", test.value, err, test.valid)
+			}
+			if minutes != test.expected {
+				t.Errorf("parseClockTime(%q) = %d, want %d", test.value, minutes, test.expected)
+			}
+		})
+	}
+}
+
+// clockTimes converts "15:04" times into minutes since midnight
+func clockTimes(t *testing.T, values []string) []int {
+	minutes := make([]int, 0, len(values))
+	for _, value := range values {
+		minute, err := parseClockTime(value)
+		if err != nil {
+			t.Fatalf("parseClockTime(%q) error = %v", value, err)
+		}
+		minutes = append(minutes, minute)
+	}
+	return minutes
+}
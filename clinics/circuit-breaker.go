@@ -0,0 +1,162 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Defaults of the circuit breakers in front of the clinic sources
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// States of a circuit breaker
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen is returned without calling the source while its circuit breaker is open
+var ErrCircuitOpen = errors.New("Clinic data provider is temporarily unavailable.")
+
+/* circuitBreaker stops calling a failing source. After failureThreshold consecutive failures it
+opens and rejects calls for openTimeout, then half-opens to let a single probe call through.
+A successful probe closes it again, a failed one opens it for another openTimeout.*/
+type circuitBreaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu          sync.Mutex
+	state       string
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	lastFailure string
+}
+
+// breakerStatus is a snapshot of a circuit breaker shown by the status endpoint
+type breakerStatus struct {
+	State       string     `json:"state"`
+	Failures    int        `json:"failures"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	LastFailure string     `json:"last_failure,omitempty"`
+}
+
+var (
+	dentalClinicBreaker = newCircuitBreaker(dentalClinicType, defaultBreakerFailureThreshold, defaultBreakerOpenTimeout)
+	vetClinicBreaker    = newCircuitBreaker(vetClinicType, defaultBreakerFailureThreshold, defaultBreakerOpenTimeout)
+)
+
+func newCircuitBreaker(name string, failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		state:            breakerClosed,
+	}
+}
+
+/* [ConfigureCircuitBreakers] - Set after how many consecutive failures the circuit breakers open
+and how long they stay open before probing the source again. Zero values keep the defaults.
+It must be called before the server starts.*/
+
+func ConfigureCircuitBreakers(failureThreshold int, openTimeout time.Duration) {
+	for _, breaker := range []*circuitBreaker{dentalClinicBreaker, vetClinicBreaker} {
+		if failureThreshold > 0 {
+			breaker.failureThreshold = failureThreshold
+		}
+		if openTimeout > 0 {
+			breaker.openTimeout = openTimeout
+		}
+	}
+}
+
+/* [execute] - Call fn if the breaker allows it and record its outcome.*/
+
+func (b *circuitBreaker) execute(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := fn()
+	b.record(err)
+	return err
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		log.Println("Circuit breaker for", b.name, "clinics is half-open, probing the source")
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		// only one probe at a time
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	// the caller going away says nothing about the health of the source
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err == nil {
+		if b.state != breakerClosed {
+			log.Println("Circuit breaker for", b.name, "clinics is closed")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastFailure = err.Error()
+	if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+		if b.state != breakerOpen {
+			log.Println("Circuit breaker for", b.name, "clinics is open after:", err)
+		}
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) status() breakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := breakerStatus{
+		State:       b.state,
+		Failures:    b.failures,
+		LastFailure: b.lastFailure,
+	}
+	if b.state != breakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
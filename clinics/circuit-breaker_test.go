@@ -0,0 +1,106 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testBreakerOpenTimeout is short enough for the breaker to half-open within a test
+const testBreakerOpenTimeout = 20 * time.Millisecond
+
+/* breakerStep is a call through a circuit breaker returning err, or a wait for the open timeout to
+pass. A probing step makes a second call while the first one runs, which has to be rejected.*/
+type breakerStep struct {
+	wait      bool
+	err       error
+	probing   bool
+	wantErr   error
+	wantState string
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	sourceErr := errors.New("upstream down")
+	fail := breakerStep{err: sourceErr, wantErr: sourceErr}
+	wait := breakerStep{wait: true}
+
+	tests := []struct {
+		name  string
+		steps []breakerStep
+	}{
+		{"opens after the failure threshold", []breakerStep{
+			withState(fail, breakerClosed),
+			withState(fail, breakerClosed),
+			withState(fail, breakerOpen),
+			{wantErr: ErrCircuitOpen, wantState: breakerOpen},
+		}},
+		{"a success resets the failures", []breakerStep{
+			withState(fail, breakerClosed),
+			withState(fail, breakerClosed),
+			{wantState: breakerClosed},
+			withState(fail, breakerClosed),
+			withState(fail, breakerClosed),
+		}},
+		{"a successful probe closes it", []breakerStep{
+			fail, fail, withState(fail, breakerOpen),
+			wait,
+			{probing: true, wantState: breakerClosed},
+			withState(fail, breakerClosed),
+		}},
+		{"a failed probe opens it again", []breakerStep{
+			fail, fail, withState(fail, breakerOpen),
+			wait,
+			{probing: true, err: sourceErr, wantErr: sourceErr, wantState: breakerOpen},
+			{wantErr: ErrCircuitOpen, wantState: breakerOpen},
+			wait,
+			{wantState: breakerClosed},
+		}},
+		{"cancelled calls are not failures", []breakerStep{
+			{err: context.Canceled, wantErr: context.Canceled, wantState: breakerClosed},
+			{err: context.Canceled, wantErr: context.Canceled, wantState: breakerClosed},
+			{err: context.Canceled, wantErr: context.Canceled, wantState: breakerClosed},
+			withState(fail, breakerClosed),
+		}},
+		{"a cancelled probe lets the next call probe", []breakerStep{
+			fail, fail, withState(fail, breakerOpen),
+			wait,
+			{probing: true, err: context.Canceled, wantErr: context.Canceled, wantState: breakerHalfOpen},
+			{probing: true, wantState: breakerClosed},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := newCircuitBreaker("test", 3, testBreakerOpenTimeout)
+			for i, step := range test.steps {
+				if step.wait {
+					time.Sleep(testBreakerOpenTimeout + 5*time.Millisecond)
+					continue
+				}
+
+				err := breaker.execute(func() error {
+					if step.probing {
+						if err := breaker.execute(func() error { return nil }); err != ErrCircuitOpen {
+							t.Errorf("step %d: call during the probe error = %v, want ErrCircuitOpen", i, err)
+						}
+					}
+					return step.err
+				})
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d: execute() error = %v, want %v", i, err, step.wantErr)
+				}
+				if step.wantState != "" {
+					if state := breaker.status().State; state != step.wantState {
+						t.Fatalf("step %d: state = %s, want %s", i, state, step.wantState)
+					}
+				}
+			}
+		})
+	}
+}
+
+func withState(step breakerStep, state string) breakerStep {
+	step.wantState = state
+	return step
+}
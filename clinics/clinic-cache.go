@@ -0,0 +1,199 @@
+package clinics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is used when the cache refresh interval is not configured
+const defaultCacheTTL = 5 * time.Minute
+
+// clinicCache holds a parsed and indexed clinic list and refreshes it from its loader
+type clinicCache struct {
+	name string
+	// load gets the data currently cached, nil at first, and returns the data to cache instead
+	load func(ctx context.Context, previous interface{}) (interface{}, error)
+
+	// refreshMu makes sure only one refresh runs at a time
+	refreshMu sync.Mutex
+
+	mu          sync.RWMutex
+	ttl         time.Duration
+	data        interface{}
+	lastRefresh time.Time
+	// refreshing is set while a background refresh started by get runs
+	refreshing bool
+}
+
+var (
+	dentalClinicCache = newClinicCache(dentalClinicType, func(ctx context.Context, previous interface{}) (interface{}, error) {
+		dentalClinicData, parsed, err := getDentalClinicList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return reuseClinicIndex(previous, parsed, func() []Clinic { return dentalClinics(dentalClinicData) }), nil
+	})
+	vetClinicCache = newClinicCache(vetClinicType, func(ctx context.Context, previous interface{}) (interface{}, error) {
+		vetClinicData, parsed, err := getVetClinicList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return reuseClinicIndex(previous, parsed, func() []Clinic { return vetClinics(vetClinicData) }), nil
+	})
+)
+
+/* [reuseClinicIndex] - Keep the cached index while the loader reuses the list it was built from,
+e.g. when the source reports no change. A new index would also make the merged index be rebuilt.*/
+
+func reuseClinicIndex(previous interface{}, parsed bool, clinics func() []Clinic) *clinicIndex {
+	if index, ok := previous.(*clinicIndex); ok && !parsed {
+		return index
+	}
+	return newClinicIndex(clinics())
+}
+
+func newClinicCache(name string, load func(ctx context.Context, previous interface{}) (interface{}, error)) *clinicCache {
+	return &clinicCache{
+		name: name,
+		load: load,
+		ttl:  defaultCacheTTL,
+	}
+}
+
+/* [StartClinicCacheRefresh] - Load the dental and vet clinic lists and keep refreshing them in
+the background on the given interval. Cached data that goes stale is also refreshed on access,
+so the service works even if this function is never called.*/
+
+func StartClinicCacheRefresh(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultCacheTTL
+	}
+
+	for _, cache := range []*clinicCache{dentalClinicCache, vetClinicCache} {
+		// refresh on access only if the background loop falls behind
+		cache.setTTL(2 * interval)
+		go cache.refreshPeriodically(interval)
+	}
+}
+
+/* [get] - Return the cached data and the time it was loaded. Data older than the ttl is still
+served right away while at most one background refresh reloads it; only callers finding nothing
+cached wait for the first load. Loads never run on the context of the caller, so a caller that
+goes away does not abort a load other callers are waiting for.*/
+
+func (c *clinicCache) get(ctx context.Context) (interface{}, time.Time, error) {
+	c.mu.RLock()
+	data, lastRefresh, ttl := c.data, c.lastRefresh, c.ttl
+	c.mu.RUnlock()
+
+	if data != nil {
+		if time.Since(lastRefresh) >= ttl {
+			c.refreshInBackground()
+		}
+		return data, lastRefresh, nil
+	}
+
+	loaded := make(chan error, 1)
+	go func() {
+		loaded <- c.refresh(context.Background())
+	}()
+	select {
+	case err := <-loaded:
+		c.mu.RLock()
+		data, lastRefresh = c.data, c.lastRefresh
+		c.mu.RUnlock()
+
+		if data == nil {
+			return nil, time.Time{}, err
+		}
+		return data, lastRefresh, nil
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	}
+}
+
+/* [refreshInBackground] - Start a refresh of stale data unless one started by get is running.*/
+
+func (c *clinicCache) refreshInBackground() {
+	c.mu.Lock()
+	if c.refreshing {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing = true
+	c.mu.Unlock()
+
+	go func() {
+		c.refresh(context.Background())
+
+		c.mu.Lock()
+		c.refreshing = false
+		c.mu.Unlock()
+	}()
+}
+
+/* [refresh] - Reload the data, keeping the previous data if loading fails.*/
+
+func (c *clinicCache) refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	// another caller may have refreshed while we were waiting for the lock
+	c.mu.RLock()
+	fresh := c.data != nil && time.Since(c.lastRefresh) < c.ttl
+	c.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	return c.forceRefresh(ctx)
+}
+
+func (c *clinicCache) forceRefresh(ctx context.Context) error {
+	c.mu.RLock()
+	previous := c.data
+	c.mu.RUnlock()
+
+	data, err := c.load(ctx, previous)
+	if err != nil {
+		log.Println("Refreshing", c.name, "clinics failed:", err)
+		return err
+	}
+
+	c.mu.Lock()
+	c.data = data
+	c.lastRefresh = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+/* [refreshPeriodically] - Background loop refreshing the cache on every tick.*/
+
+func (c *clinicCache) refreshPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.refreshMu.Lock()
+	c.forceRefresh(context.Background())
+	c.refreshMu.Unlock()
+
+	for range ticker.C {
+		c.refreshMu.Lock()
+		c.forceRefresh(context.Background())
+		c.refreshMu.Unlock()
+	}
+}
+
+func (c *clinicCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+}
+
+func (c *clinicCache) lastRefreshed() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastRefresh
+}
@@ -0,0 +1,106 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClinicCacheServesStaleDataWhileRefreshing(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := newClinicCache("test", func(ctx context.Context, previous interface{}) (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			return "first", nil
+		}
+		<-release
+		return "second", nil
+	})
+	cache.setTTL(time.Millisecond)
+
+	if data, _, err := cache.get(context.Background()); err != nil || data != "first" {
+		t.Fatalf("get() = %v, %v, want first", data, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// the refresh is blocked, every caller still gets the stale data right away
+	for i := 0; i < 5; i++ {
+		if data, _, err := cache.get(context.Background()); err != nil || data != "first" {
+			t.Fatalf("get() while refreshing = %v, %v, want first", data, err)
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for currentData(cache) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatalf("cache data = %v, want second after the refresh", currentData(cache))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if loads := atomic.LoadInt32(&loads); loads != 2 {
+		t.Errorf("loads = %d, want 2", loads)
+	}
+}
+
+func TestClinicCacheFirstLoadIgnoresCallerCancel(t *testing.T) {
+	release := make(chan struct{})
+	cache := newClinicCache("test", func(ctx context.Context, previous interface{}) (interface{}, error) {
+		select {
+		case <-release:
+			return "loaded", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, _, err := cache.get(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("get() error = %v, want context.Canceled", err)
+	}
+	close(release)
+
+	data, _, err := cache.get(context.Background())
+	if err != nil || data != "loaded" {
+		t.Fatalf("get() = %v, %v, want the load the cancelled caller started", data, err)
+	}
+}
+
+func TestClinicCacheFirstLoadFails(t *testing.T) {
+	loadErr := errors.New("upstream down")
+	cache := newClinicCache("test", func(ctx context.Context, previous interface{}) (interface{}, error) {
+		return nil, loadErr
+	})
+
+	if _, _, err := cache.get(context.Background()); !errors.Is(err, loadErr) {
+		t.Fatalf("get() error = %v, want %v", err, loadErr)
+	}
+}
+
+func TestReuseClinicIndex(t *testing.T) {
+	builds := 0
+	clinics := func() []Clinic {
+		builds++
+		return fixtureClinics(t)
+	}
+
+	first := reuseClinicIndex(nil, false, clinics)
+	if reused := reuseClinicIndex(first, false, clinics); reused != first {
+		t.Errorf("index of a reused list was rebuilt")
+	}
+	if rebuilt := reuseClinicIndex(first, true, clinics); rebuilt == first {
+		t.Errorf("index of a parsed list was reused")
+	}
+	if builds != 2 {
+		t.Errorf("builds = %d, want 2", builds)
+	}
+}
+
+func currentData(cache *clinicCache) interface{} {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	return cache.data
+}
@@ -0,0 +1,28 @@
+package clinics
+
+// Sample clinic lists served by the "memory" clinic source, in the same format as the remote files
+
+const dentalClinicsFixture = `[
+	{"name": "Good Health Home", "stateName": "Alaska", "availability": {"from": "10:00", "to": "19:30"}},
+	{"name": "Mayo Clinic", "stateName": "Florida", "availability": {"from": "09:00", "to": "20:00"}},
+	{"name": "Cleveland Clinic", "stateName": "New York", "availability": {"from": "11:00", "to": "22:00"}},
+	{"name": "Hopkins Hospital Baltimore", "stateName": "Florida", "availability": {"from": "07:00", "to": "22:00"}},
+	{"name": "Mount Sinai Hospital", "stateName": "California", "lat": 34.0754, "lng": -118.3806, "availability": {"from": "12:00", "to": "22:00"}},
+	{"name": "Tufts Medical Center", "stateName": "Kansas", "availability": {"from": "10:00", "to": "23:00"}},
+	{"name": "UAB Hospital", "stateName": "Alaska", "availability": {"from": "11:00", "to": "22:00"}},
+	{"name": "Swedish Medical Center", "stateName": "Arizona", "availability": {"from": "07:00", "to": "20:00"}},
+	{"name": "Scratchpay Test Pet Medical Center", "stateName": "California", "availability": {"from": "00:00", "to": "24:00"}},
+	{"name": "Scratchpay Official practice", "stateName": "Tennessee", "availability": {"from": "00:00", "to": "24:00"}},
+	{"name": "Lunch Break Dental", "stateName": "Nevada", "lat": 36.1699, "lng": -115.1398, "availability": [{"from": "08:00", "to": "12:00"}, {"from": "13:00", "to": "18:00"}],
+		"weeklyHours": {"sat": {"from": "09:00", "to": "13:00"}, "sun": []}, "closures": ["2026-12-25", "2027-01-01"]}
+]`
+
+const vetClinicsFixture = `[
+	{"clinicName": "Good Health Home", "stateCode": "FL", "opening": {"from": "15:00", "to": "20:00"}},
+	{"clinicName": "National Veterinary Clinic", "stateCode": "CA", "lat": 37.7749, "lng": -122.4194, "opening": {"from": "15:00", "to": "22:30"}},
+	{"clinicName": "German Pets Clinics", "stateCode": "KS", "opening": {"from": "08:00", "to": "20:00"}},
+	{"clinicName": "City Vet Clinic", "stateCode": "NV", "lat": 36.1147, "lng": -115.1728, "opening": {"from": "10:00", "to": "22:00"}},
+	{"clinicName": "Scratchpay Test Pet Medical Center", "stateCode": "CA", "opening": {"from": "00:00", "to": "24:00"}},
+	{"clinicName": "Night Owl Animal Hospital", "stateCode": "NV", "lat": 36.0395, "lng": -114.9817, "opening": {"from": "22:00", "to": "06:00"},
+		"weeklyHours": {"fri": {"from": "20:00", "to": "08:00"}, "sat": {"from": "20:00", "to": "08:00"}}}
+]`
@@ -0,0 +1,260 @@
+package clinics
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+/* clinicIndex is a clinic list with inverted indexes of the words of the clinic names and of the
+states, the term statistics of their full text, a trie of the names and a spatial grid of the
+locations. It is built once every time the cache is refreshed, so name, state, full-text and area
+searches only check the clinics that can match instead of the whole list.*/
+type clinicIndex struct {
+	clinics []Clinic
+	// lower case name word to the positions of the clinics with that word, ascending
+	nameTokens map[string][]int
+	// every name word in order, to look up words by prefix
+	sortedTokens []string
+	// lower case state name or code to the positions of the clinics in that state, ascending
+	states map[string][]int
+
+	// full-text term to the positions of the clinics with that term, ascending
+	textPostings map[string][]int
+	// full-text term to how often it is in the full text of each clinic of its postings
+	textTermFrequencies map[string][]int
+	// number of terms of the full text of every clinic
+	textLengths       []int
+	averageTextLength float64
+
+	// names finds clinic names as users type them
+	names *nameTrie
+	// grid finds clinics by their location
+	grid *spatialGrid
+}
+
+func newClinicIndex(clinics []Clinic) *clinicIndex {
+	index := &clinicIndex{
+		clinics:             clinics,
+		nameTokens:          make(map[string][]int),
+		states:              make(map[string][]int),
+		textPostings:        make(map[string][]int),
+		textTermFrequencies: make(map[string][]int),
+		textLengths:         make([]int, len(clinics)),
+	}
+	totalTextLength := 0
+
+	for i, clinic := range clinics {
+		for _, token := range strings.Fields(strings.ToLower(clinic.ClinicName())) {
+			index.nameTokens[token] = appendPosition(index.nameTokens[token], i)
+		}
+		state := strings.ToLower(clinic.ClinicState())
+		index.states[state] = appendPosition(index.states[state], i)
+		if stateCode := strings.ToLower(clinic.ClinicStateCode()); stateCode != "" {
+			index.states[stateCode] = appendPosition(index.states[stateCode], i)
+		}
+
+		terms := tokenizeText(clinicText(clinic))
+		for _, term := range terms {
+			postings := index.textPostings[term]
+			if len(postings) > 0 && postings[len(postings)-1] == i {
+				index.textTermFrequencies[term][len(postings)-1]++
+				continue
+			}
+			index.textPostings[term] = append(postings, i)
+			index.textTermFrequencies[term] = append(index.textTermFrequencies[term], 1)
+		}
+		index.textLengths[i] = len(terms)
+		totalTextLength += len(terms)
+	}
+	if len(clinics) > 0 {
+		index.averageTextLength = math.Max(1, float64(totalTextLength)/float64(len(clinics)))
+	}
+
+	index.sortedTokens = make([]string, 0, len(index.nameTokens))
+	for token := range index.nameTokens {
+		index.sortedTokens = append(index.sortedTokens, token)
+	}
+	sort.Strings(index.sortedTokens)
+
+	index.names = newNameTrie(clinics)
+	index.grid = newSpatialGrid(clinics)
+	return index
+}
+
+// appendPosition adds a clinic position once, positions are added in ascending order
+func appendPosition(positions []int, position int) []int {
+	if len(positions) > 0 && positions[len(positions)-1] == position {
+		return positions
+	}
+	return append(positions, position)
+}
+
+/* [candidates] - Clinics that can match the name and state search conditions and the full-text
+query and are inside the searched areas, in list order. Clinics found by the full-text query get its score. The search engine still
+checks the other conditions on them, so the index only rules clinics out.*/
+
+func (index *clinicIndex) candidates(searchConditionKeys searchConditions, searchOperator string) []Clinic {
+	positions, narrowed := index.fieldCandidates(searchConditionKeys, searchOperator)
+	// clinics always have to be inside the searched areas, whatever the search operator
+	if areaPositions, inArea := index.areaCandidates(searchConditionKeys); inArea {
+		if narrowed {
+			positions = intersectPositions(positions, areaPositions)
+		} else {
+			positions, narrowed = areaPositions, true
+		}
+	}
+	if len(searchConditionKeys.textTerms) == 0 {
+		if !narrowed {
+			return index.clinics
+		}
+		clinics := make([]Clinic, len(positions))
+		for i, position := range positions {
+			clinics[i] = index.clinics[position]
+		}
+		return clinics
+	}
+
+	textPositions, scores := index.textScores(searchConditionKeys.textTerms)
+	if narrowed {
+		positions = intersectPositions(positions, textPositions)
+	} else {
+		positions = textPositions
+	}
+
+	clinics := make([]Clinic, len(positions))
+	for i, position := range positions {
+		clinics[i] = index.clinics[position].withScore(scores[position])
+	}
+	return clinics
+}
+
+/* [fieldCandidates] - Positions of the clinics that can match the name and state search
+conditions. It is not narrowed when the index can not rule clinics out, e.g. for contains and
+fuzzy name matching or OR searches with times.*/
+
+func (index *clinicIndex) fieldCandidates(searchConditionKeys searchConditions, searchOperator string) ([]int, bool) {
+	if !searchConditionKeys.hasFieldSearchKeys() {
+		return nil, false
+	}
+	namePositions, nameIndexed := index.nameCandidates(searchConditionKeys)
+	statePositions, stateIndexed := index.stateCandidates(searchConditionKeys)
+
+	if searchOperator == "and" {
+		switch {
+		case nameIndexed && stateIndexed:
+			return intersectPositions(namePositions, statePositions), true
+		case nameIndexed:
+			return namePositions, true
+		case stateIndexed:
+			return statePositions, true
+		}
+		return nil, false
+	}
+
+	// any searched field can match on its own, so all of them have to be indexed
+	if searchConditionKeys.hasTimeSearchKeys() ||
+		(len(searchConditionKeys.clinicNameSearchPhases) > 0 && !nameIndexed) {
+		return nil, false
+	}
+	return unionPositions(namePositions, statePositions), true
+}
+
+/* [nameCandidates] - Positions of the clinics whose name can match any of the clinicName search
+phases. Only the exact, word and prefix modes can be looked up, the phase words have to be name
+words, and for prefix the first phase word has to start a name word.*/
+
+func (index *clinicIndex) nameCandidates(searchConditionKeys searchConditions) ([]int, bool) {
+	if len(searchConditionKeys.clinicNameSearchPhases) == 0 {
+		return nil, false
+	}
+
+	var phasePositions [][]int
+	for _, searchPhase := range searchConditionKeys.clinicNameSearchPhases {
+		searchPhaseWords := strings.Fields(searchPhase)
+		if len(searchPhaseWords) == 0 {
+			return nil, false
+		}
+
+		switch searchConditionKeys.nameMatchMode {
+		case nameMatchExact, nameMatchWord:
+			positions := index.nameTokens[searchPhaseWords[0]]
+			for _, word := range searchPhaseWords[1:] {
+				positions = intersectPositions(positions, index.nameTokens[word])
+			}
+			phasePositions = append(phasePositions, positions)
+		case nameMatchPrefix:
+			first := sort.SearchStrings(index.sortedTokens, searchPhaseWords[0])
+			for _, token := range index.sortedTokens[first:] {
+				if !strings.HasPrefix(token, searchPhaseWords[0]) {
+					break
+				}
+				phasePositions = append(phasePositions, index.nameTokens[token])
+			}
+		default:
+			return nil, false
+		}
+	}
+	return unionPositions(phasePositions...), true
+}
+
+/* [stateCandidates] - Positions of the clinics in any of the searched states, looked up by the
+code of known states and by the state as it was given.*/
+
+func (index *clinicIndex) stateCandidates(searchConditionKeys searchConditions) ([]int, bool) {
+	if len(searchConditionKeys.stateSearchPhases) == 0 {
+		return nil, false
+	}
+
+	var statePositions [][]int
+	for i, stateSearchPhase := range searchConditionKeys.stateSearchPhases {
+		statePositions = append(statePositions, index.states[stateSearchPhase])
+		if stateSearchCode := searchConditionKeys.stateSearchCodes[i]; stateSearchCode != "" {
+			statePositions = append(statePositions, index.states[strings.ToLower(stateSearchCode)])
+		}
+	}
+	return unionPositions(statePositions...), true
+}
+
+// intersectPositions keeps the positions found in both ascending lists
+func intersectPositions(a, b []int) []int {
+	positions := make([]int, 0, minInt(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			positions = append(positions, a[i])
+			i++
+			j++
+		}
+	}
+	return positions
+}
+
+// unionPositions merges ascending lists of positions into one without duplicates
+func unionPositions(lists ...[]int) []int {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+
+	total := 0
+	for _, list := range lists {
+		total += len(list)
+	}
+	positions := make([]int, 0, total)
+	for _, list := range lists {
+		positions = append(positions, list...)
+	}
+	sort.Ints(positions)
+
+	unique := positions[:0]
+	for _, position := range positions {
+		if len(unique) == 0 || unique[len(unique)-1] != position {
+			unique = append(unique, position)
+		}
+	}
+	return unique
+}
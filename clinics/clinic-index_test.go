@@ -0,0 +1,173 @@
+package clinics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// generatedClinicCount is the number of clinics of each type in generated clinic lists
+const generatedClinicCount = 25000
+
+var (
+	generatedNameWords = []string{
+		"Good", "Health", "Care", "Family", "Smile", "Bright", "Pet", "Animal", "City", "Valley",
+		"River", "Sunrise", "Oak", "Pine", "Central", "Main", "Street", "North", "South", "Harbor",
+	}
+	generatedNameKinds = []string{"Clinic", "Hospital", "Center", "Practice", "Home", "Dental", "Vet"}
+	generatedHours     = []timings{
+		{From: "08:00", To: "16:00"}, {From: "09:00", To: "17:00"}, {From: "10:00", To: "20:00"},
+		{From: "12:00", To: "22:00"}, {From: "00:00", To: "24:00"}, {From: "22:00", To: "06:00"},
+	}
+)
+
+/* [generateClinics] - Generate a dental and a vet clinic list of count clinics each, with names of
+two words and a kind, random states and opening hours. The lists are the same for the same seed.*/
+
+func generateClinics(t testing.TB, count int) []Clinic {
+	random := rand.New(rand.NewSource(1))
+	dentalList := make([]map[string]interface{}, count)
+	vetList := make([]map[string]interface{}, count)
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s %s %s",
+			generatedNameWords[random.Intn(len(generatedNameWords))],
+			generatedNameWords[random.Intn(len(generatedNameWords))],
+			generatedNameKinds[random.Intn(len(generatedNameKinds))])
+		state := usStates[random.Intn(len(usStates))]
+		hours := generatedHours[random.Intn(len(generatedHours))]
+
+		dentalList[i] = map[string]interface{}{"name": name, "stateName": state.name, "availability": hours}
+		vetList[i] = map[string]interface{}{"clinicName": name, "stateCode": state.code, "opening": hours}
+	}
+
+	dentalData, err := json.Marshal(dentalList)
+	if err != nil {
+		t.Fatalf("encoding the generated dental clinics: %v", err)
+	}
+	vetData, err := json.Marshal(vetList)
+	if err != nil {
+		t.Fatalf("encoding the generated vet clinics: %v", err)
+	}
+	dentalClinicData, err := parseDentalClinicList(dentalData)
+	if err != nil {
+		t.Fatalf("parsing the generated dental clinics: %v", err)
+	}
+	vetClinicData, err := parseVetClinicList(vetData)
+	if err != nil {
+		t.Fatalf("parsing the generated vet clinics: %v", err)
+	}
+	return mergeClinicLists(dentalClinics(dentalClinicData.([]dentalClinicInfo)), vetClinics(vetClinicData.([]vetClinicInfo)))
+}
+
+// indexedSearch checks the search conditions on the clinics the index finds
+func indexedSearch(index *clinicIndex, searchConditionKeys searchConditions, searchOperator string, onlyTimeConditionExists bool) []Clinic {
+	return searchClinics(index.candidates(searchConditionKeys, searchOperator), searchConditionKeys, searchOperator, onlyTimeConditionExists)
+}
+
+// scanSearch checks the search conditions on every clinic, like the search engine did before the index
+func scanSearch(index *clinicIndex, searchConditionKeys searchConditions, searchOperator string, onlyTimeConditionExists bool) []Clinic {
+	return searchClinics(index.clinics, searchConditionKeys, searchOperator, onlyTimeConditionExists)
+}
+
+func searchClinics(clinics []Clinic, searchConditionKeys searchConditions, searchOperator string, onlyTimeConditionExists bool) []Clinic {
+	if searchOperator == "and" {
+		return searchClinicsBasedOnAndCondition(clinics, searchConditionKeys, onlyTimeConditionExists)
+	}
+	return searchClinicsBasedOnOrCondition(clinics, searchConditionKeys)
+}
+
+var clinicIndexQueries = []string{
+	"clinicName=health",
+	"clinicName=bright smile",
+	"clinicName=good,harbor",
+	"clinicName=oak pine clinic&nameMatch=exact",
+	"clinicName=heal&nameMatch=prefix",
+	"clinicName=sun,ri&nameMatch=prefix",
+	"clinicName=alth&nameMatch=contains",
+	"state=CA",
+	"state=california,texas",
+	"state=NV&openFrom=09:00&condition=and",
+	"clinicName=pet&state=NV&condition=and",
+	"clinicName=care&state=TX&condition=or",
+	"clinicName=vet&openTo=06:00&condition=or",
+	"clinicName=home&day=sun&condition=and",
+	"openFrom=22:00&openTo=07:00",
+}
+
+func TestClinicIndexMatchesScan(t *testing.T) {
+	index := newClinicIndex(generateClinics(t, 2000))
+
+	for _, query := range clinicIndexQueries {
+		t.Run(query, func(t *testing.T) {
+			searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(t, query)
+
+			indexed := indexedSearch(index, searchConditionKeys, searchOperator, onlyTimeConditionExists)
+			scanned := scanSearch(index, searchConditionKeys, searchOperator, onlyTimeConditionExists)
+			if len(scanned) == 0 {
+				t.Fatalf("scan found no clinics, the query does not test the index")
+			}
+			if !reflect.DeepEqual(clinicNames(indexed), clinicNames(scanned)) {
+				t.Fatalf("indexed search found %d clinics, scan found %d", len(indexed), len(scanned))
+			}
+			for i := range indexed {
+				if indexed[i].ClinicScore() != scanned[i].ClinicScore() {
+					t.Errorf("score of %s = %v, scan = %v", indexed[i].ClinicName(), indexed[i].ClinicScore(), scanned[i].ClinicScore())
+				}
+			}
+		})
+	}
+}
+
+func benchmarkSearch(b *testing.B, query string, search func(*clinicIndex, searchConditions, string, bool) []Clinic) {
+	index := newClinicIndex(generateClinics(b, generatedClinicCount))
+	searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(b, query)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		search(index, searchConditionKeys, searchOperator, onlyTimeConditionExists)
+	}
+}
+
+func BenchmarkIndexedSearchByName(b *testing.B) {
+	benchmarkSearch(b, "clinicName=harbor", indexedSearch)
+}
+
+func BenchmarkScanSearchByName(b *testing.B) {
+	benchmarkSearch(b, "clinicName=harbor", scanSearch)
+}
+
+func BenchmarkIndexedSearchByState(b *testing.B) {
+	benchmarkSearch(b, "state=CA", indexedSearch)
+}
+
+func BenchmarkScanSearchByState(b *testing.B) {
+	benchmarkSearch(b, "state=CA", scanSearch)
+}
+
+func BenchmarkIndexedSearchByPrefix(b *testing.B) {
+	benchmarkSearch(b, "clinicName=harb&nameMatch=prefix", indexedSearch)
+}
+
+func BenchmarkScanSearchByPrefix(b *testing.B) {
+	benchmarkSearch(b, "clinicName=harb&nameMatch=prefix", scanSearch)
+}
+
+func benchmarkSearchClinicList(b *testing.B, query string) {
+	index := newClinicIndex(generateClinics(b, generatedClinicCount))
+	searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(b, query)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchClinicList(index, searchConditionKeys, searchOperator, onlyTimeConditionExists, &searchMeta{})
+	}
+}
+
+func BenchmarkSearchClinicListByState(b *testing.B) {
+	benchmarkSearchClinicList(b, "state=CA&limit=10")
+}
+
+func BenchmarkSearchClinicListByText(b *testing.B) {
+	benchmarkSearchClinicList(b, "q=health&limit=10")
+}
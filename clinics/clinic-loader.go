@@ -0,0 +1,78 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+/* clinicListLoader fetches a clinic list from its source through the circuit breaker and parses
+it. The last parsed list is kept so it can be reused when the source reports no change.*/
+type clinicListLoader struct {
+	name  string
+	parse func(dataByte []byte) (interface{}, error)
+
+	mu      sync.Mutex
+	data    interface{}
+	fetches int
+	parses  int
+}
+
+// loaderStatus shows how often a clinic list was actually parsed on the status endpoint
+type loaderStatus struct {
+	Fetches int `json:"fetches"`
+	Parses  int `json:"parses"`
+}
+
+func newClinicListLoader(name string, parse func(dataByte []byte) (interface{}, error)) *clinicListLoader {
+	return &clinicListLoader{name: name, parse: parse}
+}
+
+/* [load] - Fetch and parse the clinic list, reusing the last parsed list if the source returns
+ErrNotModified. It also returns whether the list was parsed again, so whatever is built from the
+list only has to be rebuilt then.*/
+
+func (l *clinicListLoader) load(ctx context.Context, source ClinicSource, breaker *circuitBreaker) (interface{}, bool, error) {
+	var dataByte []byte
+	notModified := false
+
+	// the circuit breaker stops calling the source while it keeps failing
+	err := breaker.execute(func() error {
+		var fetchErr error
+		dataByte, fetchErr = source.Fetch(ctx)
+		if errors.Is(fetchErr, ErrNotModified) {
+			notModified = true
+			return nil
+		}
+		return fetchErr
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fetches++
+	if notModified && l.data != nil {
+		log.Printf("%s clinics not modified, reusing parsed list (%d of %d fetches parsed)", l.name, l.parses, l.fetches)
+		return l.data, false, nil
+	}
+
+	data, err := l.parse(dataByte)
+	if err != nil {
+		l.data = nil
+		return nil, false, err
+	}
+	l.data = data
+	l.parses++
+	log.Printf("%s clinics parsed (%d of %d fetches parsed)", l.name, l.parses, l.fetches)
+	return data, true, nil
+}
+
+func (l *clinicListLoader) status() loaderStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return loaderStatus{Fetches: l.fetches, Parses: l.parses}
+}
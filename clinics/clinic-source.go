@@ -0,0 +1,164 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Default locations of the clinic lists
+const (
+	dentalClinicsURL = "https://storage.googleapis.com/scratchpay-code-challenge/dental-clinics.json"
+	vetClinicsURL    = "https://storage.googleapis.com/scratchpay-code-challenge/vet-clinics.json"
+)
+
+// ClinicSource provides the raw JSON list of clinics of one type
+type ClinicSource interface {
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+/* ErrNotModified is returned by a ClinicSource, along with the last data it returned, when the
+clinic list has not changed since the previous Fetch.*/
+var ErrNotModified = errors.New("clinic list not modified")
+
+var (
+	dentalClinicSource ClinicSource = NewHTTPClinicSource(dentalClinicsURL)
+	vetClinicSource    ClinicSource = NewHTTPClinicSource(vetClinicsURL)
+)
+
+/* HTTPClinicSource loads clinics from a remote JSON file. It remembers the ETag and
+Last-Modified of the last response to make conditional requests.*/
+type HTTPClinicSource struct {
+	URL    string
+	client *upstreamClient
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	lastData     []byte
+}
+
+// FileClinicSource loads clinics from a JSON file on disk
+type FileClinicSource struct {
+	Path string
+}
+
+// MemoryClinicSource serves clinics from JSON held in memory
+type MemoryClinicSource struct {
+	Data []byte
+}
+
+func NewHTTPClinicSource(url string) *HTTPClinicSource {
+	return &HTTPClinicSource{URL: url, client: defaultUpstreamClient}
+}
+
+func NewFileClinicSource(path string) *FileClinicSource {
+	return &FileClinicSource{Path: path}
+}
+
+func NewMemoryClinicSource(data []byte) *MemoryClinicSource {
+	return &MemoryClinicSource{Data: data}
+}
+
+/* [Fetch] - Download the clinic list from the url. If-None-Match and If-Modified-Since are sent
+once a list was downloaded, and ErrNotModified is returned when the upstream answers 304.*/
+
+func (s *HTTPClinicSource) Fetch(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	etag, lastModified, lastData := s.etag, s.lastModified, s.lastData
+	s.mu.Unlock()
+
+	header := http.Header{}
+	if lastData != nil {
+		if etag != "" {
+			header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	response, err := s.client.get(ctx, s.URL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotModified {
+		return lastData, ErrNotModified
+	}
+
+	s.mu.Lock()
+	s.etag = response.Header.Get("ETag")
+	s.lastModified = response.Header.Get("Last-Modified")
+	s.lastData = response.Body
+	s.mu.Unlock()
+
+	return response.Body, nil
+}
+
+/* [Fetch] - Read the clinic list from the file.*/
+
+func (s *FileClinicSource) Fetch(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(s.Path)
+}
+
+/* [Fetch] - Return the clinic list held in memory.*/
+
+func (s *MemoryClinicSource) Fetch(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Data, nil
+}
+
+/* [ConfigureClinicSources] - Select the source of the dental and vet clinic lists. Each source
+can be one of:
+	a) an http(s) url of a JSON file
+	b) "file:<path>" or a plain path of a JSON file on disk
+	c) "memory" for the built in sample clinics
+An empty value keeps the default source. It must be called before the server starts.*/
+
+func ConfigureClinicSources(dentalSource, vetSource string) error {
+	if dentalSource != "" {
+		source, err := newClinicSource(dentalSource, dentalClinicsFixture)
+		if err != nil {
+			return fmt.Errorf("dental clinic source: %v", err)
+		}
+		dentalClinicSource = source
+	}
+
+	if vetSource != "" {
+		source, err := newClinicSource(vetSource, vetClinicsFixture)
+		if err != nil {
+			return fmt.Errorf("vet clinic source: %v", err)
+		}
+		vetClinicSource = source
+	}
+	return nil
+}
+
+/* [newClinicSource] - Create a ClinicSource from its configuration value.*/
+
+func newClinicSource(config string, fixture string) (ClinicSource, error) {
+	switch {
+	case strings.HasPrefix(config, "http://") || strings.HasPrefix(config, "https://"):
+		return NewHTTPClinicSource(config), nil
+	case config == "memory":
+		return NewMemoryClinicSource([]byte(fixture)), nil
+	case strings.HasPrefix(config, "file://"):
+		return NewFileClinicSource(strings.TrimPrefix(config, "file://")), nil
+	case strings.HasPrefix(config, "file:"):
+		return NewFileClinicSource(strings.TrimPrefix(config, "file:")), nil
+	case strings.Contains(config, "://"):
+		return nil, fmt.Errorf("unsupported source %q", config)
+	default:
+		return NewFileClinicSource(config), nil
+	}
+}
@@ -0,0 +1,123 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+const testLastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+/* conditionalServer serves body with an ETag of version and a Last-Modified header, and answers
+304 when both conditional headers of a request match. It records the headers it was sent.*/
+type conditionalServer struct {
+	mu      sync.Mutex
+	body    string
+	version string
+	headers []http.Header
+}
+
+func (s *conditionalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headers = append(s.headers, r.Header.Clone())
+	etag := `"` + s.version + `"`
+	if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == testLastModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", testLastModified)
+	w.Write([]byte(s.body))
+}
+
+func (s *conditionalServer) update(body string, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.version = body, version
+}
+
+func (s *conditionalServer) request(i int) http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.headers[i]
+}
+
+func TestHTTPClinicSourceFetchConditional(t *testing.T) {
+	server := &conditionalServer{body: vetClinicsFixture, version: "1"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	source := NewHTTPClinicSource(httpServer.URL)
+
+	data, err := source.Fetch(context.Background())
+	if err != nil || string(data) != vetClinicsFixture {
+		t.Fatalf("first Fetch() = %d bytes, %v, want the clinic list", len(data), err)
+	}
+	if header := server.request(0); header.Get("If-None-Match") != "" || header.Get("If-Modified-Since") != "" {
+		t.Errorf("first request sent If-None-Match %q, If-Modified-Since %q, want none", header.Get("If-None-Match"), header.Get("If-Modified-Since"))
+	}
+
+	data, err = source.Fetch(context.Background())
+	if !errors.Is(err, ErrNotModified) || string(data) != vetClinicsFixture {
+		t.Fatalf("second Fetch() = %d bytes, %v, want the last list and ErrNotModified", len(data), err)
+	}
+	if header := server.request(1); header.Get("If-None-Match") != `"1"` || header.Get("If-Modified-Since") != testLastModified {
+		t.Errorf("second request sent If-None-Match %q, If-Modified-Since %q, want %q, %q",
+			header.Get("If-None-Match"), header.Get("If-Modified-Since"), `"1"`, testLastModified)
+	}
+
+	server.update(dentalClinicsFixture, "2")
+	data, err = source.Fetch(context.Background())
+	if err != nil || string(data) != dentalClinicsFixture {
+		t.Fatalf("Fetch() after a change = %d bytes, %v, want the new list", len(data), err)
+	}
+	if header := server.request(2); header.Get("If-None-Match") != `"1"` {
+		t.Errorf("third request sent If-None-Match %q, want %q", header.Get("If-None-Match"), `"1"`)
+	}
+}
+
+func TestClinicListLoaderReusesListWhenNotModified(t *testing.T) {
+	server := &conditionalServer{body: vetClinicsFixture, version: "1"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	source := NewHTTPClinicSource(httpServer.URL)
+	loader := newClinicListLoader("test", parseVetClinicList)
+	breaker := newCircuitBreaker("test", defaultBreakerFailureThreshold, defaultBreakerOpenTimeout)
+
+	tests := []struct {
+		name       string
+		version    string
+		wantParsed bool
+		wantStatus loaderStatus
+	}{
+		{"first load", "1", true, loaderStatus{Fetches: 1, Parses: 1}},
+		{"not modified", "1", false, loaderStatus{Fetches: 2, Parses: 1}},
+		{"not modified again", "1", false, loaderStatus{Fetches: 3, Parses: 1}},
+		{"modified", "2", true, loaderStatus{Fetches: 4, Parses: 2}},
+	}
+
+	var previous []vetClinicInfo
+	for _, test := range tests {
+		server.update(vetClinicsFixture, test.version)
+		data, parsed, err := loader.load(context.Background(), source, breaker)
+		if err != nil {
+			t.Fatalf("%s: load() error = %v", test.name, err)
+		}
+		clinics := data.([]vetClinicInfo)
+		if parsed != test.wantParsed {
+			t.Errorf("%s: parsed = %v, want %v", test.name, parsed, test.wantParsed)
+		}
+		// a reused list is the very same slice
+		if reused := previous != nil && &clinics[0] == &previous[0]; reused == test.wantParsed {
+			t.Errorf("%s: reused the last parsed list = %v, want %v", test.name, reused, !test.wantParsed)
+		}
+		if status := loader.status(); status != test.wantStatus {
+			t.Errorf("%s: status = %+v, want %+v", test.name, status, test.wantStatus)
+		}
+		previous = clinics
+	}
+}
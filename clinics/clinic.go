@@ -0,0 +1,144 @@
+package clinics
+
+/* Clinic is a clinic of any type as seen by the search engine, which filters, sorts and pages every
+clinic type the same way. A new clinic type only needs to implement it.*/
+type Clinic interface {
+	// ClinicName is the name of the clinic
+	ClinicName() string
+	// ClinicState is the full name of the state of the clinic, or the state as given when unknown
+	ClinicState() string
+	// ClinicStateCode is the two-letter code of the state of the clinic, empty when unknown
+	ClinicStateCode() string
+	// ClinicType is the type of the clinic, e.g. dental or vet
+	ClinicType() string
+	// ClinicScore is the relevance of the clinic for the current search
+	ClinicScore() float64
+
+	// coordinates is the location of the clinic, if it has one
+	coordinates() (geoPoint, bool)
+	// approximateLocation is set when the coordinates are the center of the state of the clinic
+	approximateLocation() bool
+	// distance is the distance of the clinic from the searched location in kilometers, if measured
+	distance() (float64, bool)
+
+	// openingSchedule is the parsed availability, weekly hours and closures of the clinic
+	openingSchedule() clinicSchedule
+	// withScore returns a copy of the clinic with the given relevance
+	withScore(score float64) Clinic
+	// withDistance returns a copy of the clinic with the given distance from the searched location
+	withDistance(distanceKm float64) Clinic
+}
+
+func (clinic dentalClinicInfo) ClinicName() string              { return clinic.Name }
+func (clinic dentalClinicInfo) ClinicState() string             { return clinic.State }
+func (clinic dentalClinicInfo) ClinicStateCode() string         { return clinic.StateCode }
+func (clinic dentalClinicInfo) ClinicType() string              { return dentalClinicType }
+func (clinic dentalClinicInfo) ClinicScore() float64            { return clinic.Score }
+func (clinic dentalClinicInfo) openingSchedule() clinicSchedule { return clinic.schedule }
+
+func (clinic dentalClinicInfo) withScore(score float64) Clinic {
+	clinic.Score = score
+	return clinic
+}
+
+func (clinic dentalClinicInfo) coordinates() (geoPoint, bool) {
+	return geoPointOf(clinic.Lat, clinic.Lng)
+}
+
+func (clinic dentalClinicInfo) approximateLocation() bool { return clinic.ApproximateLocation }
+
+func (clinic dentalClinicInfo) distance() (float64, bool) {
+	if clinic.DistanceKm == nil {
+		return 0, false
+	}
+	return *clinic.DistanceKm, true
+}
+
+func (clinic dentalClinicInfo) withDistance(distanceKm float64) Clinic {
+	clinic.DistanceKm = &distanceKm
+	return clinic
+}
+
+func (clinic vetClinicInfo) ClinicName() string              { return clinic.Name }
+func (clinic vetClinicInfo) ClinicStateCode() string         { return clinic.stateCode() }
+func (clinic vetClinicInfo) ClinicType() string              { return vetClinicType }
+func (clinic vetClinicInfo) ClinicScore() float64            { return clinic.Score }
+func (clinic vetClinicInfo) openingSchedule() clinicSchedule { return clinic.schedule }
+
+func (clinic vetClinicInfo) ClinicState() string {
+	return firstNonEmpty(clinic.StateName, clinic.State)
+}
+
+func (clinic vetClinicInfo) withScore(score float64) Clinic {
+	clinic.Score = score
+	return clinic
+}
+
+func (clinic vetClinicInfo) coordinates() (geoPoint, bool) {
+	return geoPointOf(clinic.Lat, clinic.Lng)
+}
+
+func (clinic vetClinicInfo) approximateLocation() bool { return clinic.ApproximateLocation }
+
+func (clinic vetClinicInfo) distance() (float64, bool) {
+	if clinic.DistanceKm == nil {
+		return 0, false
+	}
+	return *clinic.DistanceKm, true
+}
+
+func (clinic vetClinicInfo) withDistance(distanceKm float64) Clinic {
+	clinic.DistanceKm = &distanceKm
+	return clinic
+}
+
+func (clinic clinicInfo) ClinicName() string              { return clinic.Name }
+func (clinic clinicInfo) ClinicStateCode() string         { return clinic.StateCode }
+func (clinic clinicInfo) ClinicType() string              { return clinic.Type }
+func (clinic clinicInfo) ClinicScore() float64            { return clinic.Score }
+func (clinic clinicInfo) openingSchedule() clinicSchedule { return clinic.schedule }
+
+func (clinic clinicInfo) ClinicState() string {
+	return firstNonEmpty(clinic.StateName, clinic.State)
+}
+
+func (clinic clinicInfo) withScore(score float64) Clinic {
+	clinic.Score = score
+	return clinic
+}
+
+func (clinic clinicInfo) coordinates() (geoPoint, bool) {
+	return geoPointOf(clinic.Lat, clinic.Lng)
+}
+
+func (clinic clinicInfo) approximateLocation() bool { return clinic.ApproximateLocation }
+
+func (clinic clinicInfo) distance() (float64, bool) {
+	if clinic.DistanceKm == nil {
+		return 0, false
+	}
+	return *clinic.DistanceKm, true
+}
+
+func (clinic clinicInfo) withDistance(distanceKm float64) Clinic {
+	clinic.DistanceKm = &distanceKm
+	return clinic
+}
+
+// dentalClinics wraps a list of dental clinics for the search engine
+func dentalClinics(clinicsData []dentalClinicInfo) []Clinic {
+	clinics := make([]Clinic, len(clinicsData))
+	for i := range clinicsData {
+		clinics[i] = clinicsData[i]
+	}
+	return clinics
+}
+
+// vetClinics wraps a list of vet clinics for the search engine
+func vetClinics(clinicsData []vetClinicInfo) []Clinic {
+	clinics := make([]Clinic, len(clinicsData))
+	for i := range clinicsData {
+		clinics[i] = clinicsData[i]
+	}
+	return clinics
+}
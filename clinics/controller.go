@@ -2,14 +2,23 @@ package clinics
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 )
 
 // ResponseData Struct is used to store response data
 type ResponseData struct {
-	StatusCode int         `json:"status_code"`
-	Status     bool        `json:"status"`
-	Result     interface{} `json:"result"`
+	StatusCode    int         `json:"status_code"`
+	Status        bool        `json:"status"`
+	Result        interface{} `json:"result"`
+	Total         *int        `json:"total,omitempty"`
+	NextCursor    string      `json:"next_cursor,omitempty"`
+	HasMore       *bool       `json:"has_more,omitempty"`
+	LastRefreshed *time.Time  `json:"last_refreshed,omitempty"`
+
+	Facets             map[string][]facetCount `json:"facets,omitempty"`
+	UnavailableSources []string                `json:"unavailable_sources,omitempty"`
 }
 
 // ResponseError is used to store error
@@ -20,28 +29,67 @@ type ResponseError struct {
 }
 
 func SearchDentalClinicController(w http.ResponseWriter, r *http.Request) {
-	data, statusCode, err := SearchDentalClinics(r)
+	data, meta, statusCode, err := SearchDentalClinics(r)
+	writeClinicSearchResponse(w, r, data, meta, statusCode, err)
+}
+
+func SearchVetClinicController(w http.ResponseWriter, r *http.Request) {
+	data, meta, statusCode, err := SearchVetClinics(r)
+	writeClinicSearchResponse(w, r, data, meta, statusCode, err)
+}
+
+func SearchClinicController(w http.ResponseWriter, r *http.Request) {
+	data, meta, statusCode, err := SearchClinics(r)
+	writeClinicSearchResponse(w, r, data, meta, statusCode, err)
+}
+
+func SuggestClinicController(w http.ResponseWriter, r *http.Request) {
+	data, meta, statusCode, err := SuggestClinicNames(r)
+	writeSearchResponse(w, data, meta, statusCode, err)
+}
+
+func ClinicStatusController(w http.ResponseWriter, r *http.Request) {
+	resData := ResponseData{
+		StatusCode: 200,
+		Status:     true,
+		Result:     GetClinicSourcesStatus(),
+	}
+	json.NewEncoder(w).Encode(resData)
+}
+
+/* [writeClinicSearchResponse] - Common function to write the clinics found by a search in the
+format asked for by the format query param or the Accept header: ResponseData, a GeoJSON
+FeatureCollection, CSV or NDJSON. Errors are always written as ResponseError.*/
+
+func writeClinicSearchResponse(w http.ResponseWriter, r *http.Request, clinics []Clinic, meta searchMeta, statusCode int, err error) {
+	w.Header().Add("Vary", "Accept")
+	mediaType, formatErr := responseMediaType(r)
+	if formatErr != nil {
+		statusCode, err = 400, formatErr
+	}
 	if err != nil {
-		w.WriteHeader(statusCode)
-		errData := ResponseError{
-			StatusCode: statusCode,
-			Status:     false,
-			Message:    err.Error(),
-		}
-		json.NewEncoder(w).Encode(errData)
-	} else {
-		resData := ResponseData{
-			StatusCode: statusCode,
-			Status:     true,
-			Result:     data,
-		}
-		json.NewEncoder(w).Encode(resData)
+		writeSearchResponse(w, nil, meta, statusCode, err)
+		return
+	}
+
+	switch mediaType {
+	case geoJSONMediaType:
+		writeGeoJSONResponse(w, clinics, meta, statusCode)
+	case csvMediaType:
+		writeCSVResponse(w, clinics, meta, statusCode)
+	case ndjsonMediaType:
+		writeNDJSONResponse(w, clinics, meta, statusCode)
+	default:
+		writeSearchResponse(w, clinics, meta, statusCode, nil)
 	}
 }
 
-func SearchVetClinicController(w http.ResponseWriter, r *http.Request) {
-	data, statusCode, err := SearchVetClinics(r)
+/* [writeSearchResponse] - Common function to write the result of a clinic search, either as
+ResponseData or as ResponseError.*/
+
+func writeSearchResponse(w http.ResponseWriter, data interface{}, meta searchMeta, statusCode int, err error) {
 	if err != nil {
+		statusCode = errorStatusCode(err, statusCode)
 		w.WriteHeader(statusCode)
 		errData := ResponseError{
 			StatusCode: statusCode,
@@ -54,7 +102,31 @@ func SearchVetClinicController(w http.ResponseWriter, r *http.Request) {
 			StatusCode: statusCode,
 			Status:     true,
 			Result:     data,
+			Total:      &meta.total,
+			NextCursor: meta.nextCursor,
+			HasMore:    &meta.hasMore,
+			Facets:     meta.facets,
+
+			UnavailableSources: meta.unavailableSources,
+		}
+		if !meta.lastRefreshed.IsZero() {
+			resData.LastRefreshed = &meta.lastRefreshed
 		}
 		json.NewEncoder(w).Encode(resData)
 	}
 }
+
+/* [errorStatusCode] - Map an error returned by a service to a response status code. Upstream
+failures are answered with 502/504 and an open circuit breaker with 503, instead of the status
+suggested by the service.*/
+
+func errorStatusCode(err error, statusCode int) int {
+	if errors.Is(err, ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.HTTPStatusCode()
+	}
+	return statusCode
+}
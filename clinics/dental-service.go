@@ -1,10 +1,9 @@
 package clinics
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,56 +11,145 @@ import (
 )
 
 type dentalClinicInfo struct {
-	Name        string  `json:"name"`
-	State       string  `json:"stateName"`
-	Availablity timings `json:"availability"`
+	Name                string       `json:"name"`
+	State               string       `json:"stateName"`
+	StateCode           string       `json:"stateCode,omitempty"`
+	Availablity         openingHours `json:"availability"`
+	WeeklyHours         weeklyHours  `json:"weeklyHours,omitempty"`
+	Closures            []string     `json:"closures,omitempty"`
+	Score               float64      `json:"score,omitempty"`
+	Lat                 *float64     `json:"lat,omitempty"`
+	Lng                 *float64     `json:"lng,omitempty"`
+	ApproximateLocation bool         `json:"approximateLocation,omitempty"`
+	DistanceKm          *float64     `json:"distanceKm,omitempty"`
+
+	schedule clinicSchedule
 }
 
-type timings struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-}
+var dentalClinicLoader = newClinicListLoader(dentalClinicType, parseDentalClinicList)
 
 type searchConditions struct {
-	clinicNameSearchPhase string
-	stateSearchPhase      string
-	timeFromStr           string
-	timeToStr             string
-	timeFrom              time.Time
-	timeTo                time.Time
+	clinicNameSearchPhases []string
+	stateSearchPhases      []string
+	stateSearchCodes       []string
+	openFrom               []int
+	openTo                 []int
+	limit                  int
+	offset                 int
+	sortKeys               []sortKey
+	nameMatchMode          string
+	fuzzyDistance          int
+	dayStr                 string
+	day                    time.Weekday
+	date                   string
+	openAtStr              string
+	openAt                 time.Time
+	timeMatchMode          string
+	filter                 filterExpr
+	textTerms              []string
+	facets                 []string
+	origin                 *geoPoint
+	radiusKm               float64
+	areas                  []geoArea
+}
+
+// searchMeta carries response metadata from the services to the controllers
+type searchMeta struct {
+	lastRefreshed time.Time
+	total         int
+	nextCursor    string
+	hasMore       bool
+	facets        map[string][]facetCount
+	// clinic types left out of a search of all clinics because they could not be loaded
+	unavailableSources []string
+}
+
+/* [hasSearchKeys] - Check if any search condition is set. Paging params are not search
+conditions.*/
+
+func (searchConditionKeys searchConditions) hasSearchKeys() bool {
+	return searchConditionKeys.hasFieldSearchKeys() || searchConditionKeys.hasOpeningSearchKeys()
+}
+
+/* [hasConditionKeys] - Check if any param the condition param can be given with is set: a search
+condition, the filter expression, the full-text query, the location to search around or an area
+to search in.*/
+
+func (searchConditionKeys searchConditions) hasConditionKeys() bool {
+	return searchConditionKeys.hasSearchKeys() || searchConditionKeys.filter != nil ||
+		len(searchConditionKeys.textTerms) > 0 || searchConditionKeys.origin != nil ||
+		len(searchConditionKeys.areas) > 0
+}
+
+/* [hasFieldSearchKeys] - Check if any of the search conditions combined by the condition operator
+is set. The day, date, openNow and openAt conditions always have to match.*/
+
+func (searchConditionKeys searchConditions) hasFieldSearchKeys() bool {
+	return len(searchConditionKeys.clinicNameSearchPhases) > 0 || len(searchConditionKeys.stateSearchPhases) > 0 ||
+		searchConditionKeys.hasTimeSearchKeys()
+}
+
+// hasTimeSearchKeys checks if any of the openFrom and openTo conditions is set
+func (searchConditionKeys searchConditions) hasTimeSearchKeys() bool {
+	return len(searchConditionKeys.openFrom) > 0 || len(searchConditionKeys.openTo) > 0
+}
+
+// hasOpeningSearchKeys checks if any of the day, date, openNow and openAt conditions is set
+func (searchConditionKeys searchConditions) hasOpeningSearchKeys() bool {
+	return searchConditionKeys.dayStr != "" || searchConditionKeys.openAtStr != ""
+}
+
+// stateName is the state name of the clinic if it is a known state
+func (clinic dentalClinicInfo) stateName() string {
+	if clinic.StateCode == "" {
+		return ""
+	}
+	return clinic.State
 }
 
 /*================================================================================================
 			[SearchDentalClinics] - Search Dental Clinics
 	1) Fetch all clinics if no search condition is provided
 	2) Fetch clinincs which satisfy search conditions
+	3) Sort the clinics and return the requested page
 ================================================================================================*/
-func SearchDentalClinics(r *http.Request) ([]dentalClinicInfo, int, error) {
-	var filteredClinicData = []dentalClinicInfo{}
-
+func SearchDentalClinics(r *http.Request) ([]Clinic, searchMeta, int, error) {
 	queryParams := r.URL.Query()
 	searchConditionKeys, searchOperator, onlyTimeConditionExists, err := validateQueryParams(queryParams)
 	if err != nil {
-		return nil, 400, err
+		return nil, searchMeta{}, 400, err
 	}
 
-	dentalClinicData, err := getDentalClinicList()
+	dentalClinicIndex, lastRefreshed, err := getCachedDentalClinicList(r.Context())
 	if err != nil {
-		return nil, 500, err
+		return nil, searchMeta{}, 500, err
 	}
+	meta := searchMeta{lastRefreshed: lastRefreshed}
 
-	// return all clinics if there is no search condition
-	if len(r.URL.Query()) == 0 {
-		return dentalClinicData, 200, nil
+	result := searchClinicList(dentalClinicIndex, searchConditionKeys, searchOperator, onlyTimeConditionExists, &meta)
+	return result, meta, 200, nil
+}
+
+/* [queryParamValues] - Get every value of a query param, given as repeated params or as comma
+separated lists. The values are nil when any of them is empty.*/
+
+func queryParamValues(queryParams url.Values, key string) ([]string, bool) {
+	keys, ok := queryParams[key]
+	if !ok {
+		return nil, false
 	}
 
-	//conditional functional call, based on search operator
-	if searchOperator == "and" {
-		filteredClinicData = searchClinicsBasedOnAndCondition(dentalClinicData, searchConditionKeys, onlyTimeConditionExists)
-	} else {
-		filteredClinicData = searchClinicsBasedOnOrCondition(dentalClinicData, searchConditionKeys)
+	var values []string
+	for _, key := range keys {
+		for _, value := range strings.Split(key, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil, true
+			}
+			values = append(values, value)
+		}
 	}
-	return filteredClinicData, 200, nil
+	return values, true
 }
 
 /* [validateQueryParams] -  It is a Common Function called from both dental-service and
@@ -72,66 +160,114 @@ func validateQueryParams(queryParams url.Values) (searchConditions, string, bool
 	searchOperator := "or"
 	onlyTimeConditionExists := true
 
-	//Query params for data to be searched
-	if keys, ok := queryParams["clinicName"]; ok {
-		if len(keys[0]) >= 1 {
+	//Query params for data to be searched, each of them can have several values
+	if keys, ok := queryParamValues(queryParams, "clinicName"); ok {
+		if keys != nil {
 			onlyTimeConditionExists = false
-			searchConditionKeys.clinicNameSearchPhase = strings.ToLower(keys[0])
+			for _, key := range keys {
+				searchConditionKeys.clinicNameSearchPhases = append(searchConditionKeys.clinicNameSearchPhases, strings.ToLower(key))
+			}
 		} else {
 			err := errors.New("Please provide clinic name for search.")
 			return searchConditions{}, "", false, err
 		}
 	}
 
-	if keys, ok := queryParams["state"]; ok {
-		if len(keys[0]) >= 1 {
+	if keys, ok := queryParamValues(queryParams, "state"); ok {
+		if keys != nil {
 			onlyTimeConditionExists = false
-			searchConditionKeys.stateSearchPhase = strings.ToLower(keys[0])
+			for _, key := range keys {
+				stateSearchCode := ""
+				if state, ok := normalizeState(key); ok {
+					stateSearchCode = state.code
+				}
+				searchConditionKeys.stateSearchPhases = append(searchConditionKeys.stateSearchPhases, strings.ToLower(key))
+				searchConditionKeys.stateSearchCodes = append(searchConditionKeys.stateSearchCodes, stateSearchCode)
+			}
 		} else {
 			err := errors.New("Please provide state for search.")
 			return searchConditions{}, "", false, err
 		}
 	}
 
-	if keys, ok := queryParams["openFrom"]; ok {
-		if len(keys[0]) >= 1 {
-			var err error
-			searchConditionKeys.timeFromStr = keys[0]
-			// convert string to time format
-			searchConditionKeys.timeFrom, err = time.Parse("15:04", keys[0])
-			if err != nil {
-				fmt.Println(err)
-				err := errors.New("Please provide time in hour and minute format.")
-				return searchConditions{}, "", false, err
+	if keys, ok := queryParamValues(queryParams, "openFrom"); ok {
+		if keys != nil {
+			for _, key := range keys {
+				// convert string to minutes since midnight
+				openFrom, err := parseClockTime(key)
+				if err != nil {
+					err := errors.New("Please provide time in hour and minute format.")
+					return searchConditions{}, "", false, err
+				}
+				searchConditionKeys.openFrom = append(searchConditionKeys.openFrom, openFrom)
 			}
-			//Subtract 1 sec
-			searchConditionKeys.timeFrom = searchConditionKeys.timeFrom.Add(-time.Second * 1)
 		} else {
 			err := errors.New("Please provide open from for search.")
 			return searchConditions{}, "", false, err
 		}
 	}
 
-	if keys, ok := queryParams["openTo"]; ok {
-		if len(keys[0]) >= 1 {
-			var err error
-			searchConditionKeys.timeToStr = keys[0]
-			// convert string to time format
-			searchConditionKeys.timeTo, err = time.Parse("15:04", keys[0])
-			if err != nil {
-				err := errors.New("Please provide time in hour and minute format.")
-				return searchConditions{}, "", false, err
+	if keys, ok := queryParamValues(queryParams, "openTo"); ok {
+		if keys != nil {
+			for _, key := range keys {
+				// convert string to minutes since midnight
+				openTo, err := parseClockTime(key)
+				if err != nil {
+					err := errors.New("Please provide time in hour and minute format.")
+					return searchConditions{}, "", false, err
+				}
+				searchConditionKeys.openTo = append(searchConditionKeys.openTo, openTo)
 			}
-			//Add 1 sec
-			searchConditionKeys.timeTo = searchConditionKeys.timeTo.Add(time.Second * 1)
 		} else {
 			err := errors.New("Please provide open to for search.")
 			return searchConditions{}, "", false, err
 		}
 	}
 
+	if err := validateTimeMatchParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+
+	//Query params for opening day and time
+	if err := validateDayParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validateOpenAtParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+
+	//Query params for the filter expression and the full-text query
+	if err := validateFilterParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validateFullTextParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+
+	//Query params for the location to search around and the areas to search in
+	if err := validateGeoParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validateAreaParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+
+	//Query params for name matching, sorting, pagination and facets
+	if err := validateNameMatchParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validateSortParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validatePageParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+	if err := validateFacetParams(queryParams, &searchConditionKeys); err != nil {
+		return searchConditions{}, "", false, err
+	}
+
 	if keys, ok := queryParams["condition"]; ok {
-		if (searchConditions{} != searchConditionKeys) { // check if the stuct is empty
+		if searchConditionKeys.hasConditionKeys() { // check if any search key is provided
 			if len(keys[0]) > 0 {
 				if len(queryParams) == 1 {
 					searchOperator = "or"
@@ -156,166 +292,49 @@ func validateQueryParams(queryParams url.Values) (searchConditions, string, bool
 	return searchConditionKeys, searchOperator, onlyTimeConditionExists, nil
 }
 
-/* [getDentalClinicList] - Get list of all dental clinics from a url.*/
+/* [getCachedDentalClinicList] - Get the indexed list of all dental clinics from the cache, along with the
+time the list was loaded.*/
 
-func getDentalClinicList() ([]dentalClinicInfo, error) {
-	request, err := http.NewRequest("GET", "https://storage.googleapis.com/scratchpay-code-challenge/dental-clinics.json", nil)
-	request.Header.Set("Content-Type", "application/json")
+func getCachedDentalClinicList(ctx context.Context) (*clinicIndex, time.Time, error) {
+	data, lastRefreshed, err := dentalClinicCache.get(ctx)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
+	return data.(*clinicIndex), lastRefreshed, nil
+}
 
-	// send HTTP request using request object
-	res, err := http.DefaultClient.Do(request)
-	if err != nil {
-		fmt.Println(err)
-		err := errors.New("There is some issue.")
-		return nil, err
-	}
+/* [getDentalClinicList] - Get list of all dental clinics from its configured source, and whether it
+was parsed again instead of reused.*/
 
-	// read response body
-	dataByte, err := ioutil.ReadAll(res.Body)
+func getDentalClinicList(ctx context.Context) ([]dentalClinicInfo, bool, error) {
+	data, parsed, err := dentalClinicLoader.load(ctx, dentalClinicSource, dentalClinicBreaker)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	res.Body.Close()
+	return data.([]dentalClinicInfo), parsed, nil
+}
+
+/* [parseDentalClinicList] - Convert the raw clinic list into dental clinics.*/
 
+func parseDentalClinicList(dataByte []byte) (interface{}, error) {
 	// convert byte array into json format
 	responseData := make([]dentalClinicInfo, 0)
-	err = json.Unmarshal(dataByte, &responseData)
+	err := json.Unmarshal(dataByte, &responseData)
 	if err != nil {
 		return nil, err
 	}
 
-	return responseData, nil
-}
-
-/* [searchClinicsBasedOnAndCondition] - This function is used to filter out clinics based on
-search conditions and search operator = AND.*/
-
-func searchClinicsBasedOnAndCondition(clinicsData []dentalClinicInfo, searchConditionKeys searchConditions, onlyTimeConditionExists bool) []dentalClinicInfo {
-	filteredData := make([]dentalClinicInfo, 0)
-
-	for i := range clinicsData {
-
-		// Search condition check for clininc name
-		isSearchConditionMatched := false
-
-		clinicNameLowerCase := strings.ToLower(clinicsData[i].Name)
-		if searchConditionKeys.clinicNameSearchPhase != "" {
-			if searchConditionKeys.clinicNameSearchPhase == clinicNameLowerCase {
-				isSearchConditionMatched = true
-			} else {
-				clinicNameSubStrings := strings.Split(clinicNameLowerCase, " ")
-				for j := range clinicNameSubStrings {
-					if searchConditionKeys.clinicNameSearchPhase == clinicNameSubStrings[j] {
-						isSearchConditionMatched = true
-					}
-				}
-			}
-		}
-
-		// Search condition check for state
-		if searchConditionKeys.stateSearchPhase != "" {
-			if isSearchConditionMatched || searchConditionKeys.clinicNameSearchPhase == "" {
-				if searchConditionKeys.stateSearchPhase == strings.ToLower(clinicsData[i].State) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			}
-		}
-
-		// Search condition check for opening and closing time
-
-		// convert time availability data from string to time format
-		availableFrom, _ := time.Parse("15:04", clinicsData[i].Availablity.From)
-		availableTo, _ := time.Parse("15:04", clinicsData[i].Availablity.To)
-
-		if onlyTimeConditionExists || isSearchConditionMatched {
-			if searchConditionKeys.timeFromStr != "" && searchConditionKeys.timeToStr != "" {
-				if availableFrom.After(searchConditionKeys.timeFrom) &&
-					availableTo.Before(searchConditionKeys.timeTo) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			} else if searchConditionKeys.timeFromStr != "" {
-				if availableFrom.After(searchConditionKeys.timeFrom) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			} else if searchConditionKeys.timeToStr != "" {
-				if availableTo.Before(searchConditionKeys.timeTo) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			}
-		}
-
-		// Search condition check for time
-
-		if isSearchConditionMatched {
-			filteredData = append(filteredData, clinicsData[i])
+	// dental clinics come with the state name, add the code
+	for i := range responseData {
+		if state, ok := normalizeState(responseData[i].State); ok {
+			responseData[i].State = state.name
+			responseData[i].StateCode = state.code
 		}
+		responseData[i].schedule = newClinicSchedule(dentalClinicType, responseData[i].Name, responseData[i].StateCode,
+			responseData[i].Availablity, responseData[i].WeeklyHours, responseData[i].Closures)
+		responseData[i].Lat, responseData[i].Lng, responseData[i].ApproximateLocation = locateClinic(dentalClinicType,
+			responseData[i].Name, responseData[i].Lat, responseData[i].Lng, responseData[i].StateCode)
 	}
-	return filteredData
-}
-
-/* [searchClinicsBasedOnOrCondition] - This function is used to filter out clinics based on
-search conditions and search operator = OR.*/
-
-func searchClinicsBasedOnOrCondition(clinicsData []dentalClinicInfo, searchConditionKeys searchConditions) []dentalClinicInfo {
-	filteredData := make([]dentalClinicInfo, 0)
-
-	for i := range clinicsData {
-
-		// Search condition check for clininc name
-		isSearchConditionMatched := false
-
-		clinicNameLowerCase := strings.ToLower(clinicsData[i].Name)
-		if searchConditionKeys.clinicNameSearchPhase == clinicNameLowerCase {
-			isSearchConditionMatched = true
-		} else {
-			clinicNameSubStrings := strings.Split(clinicNameLowerCase, " ")
-			for j := range clinicNameSubStrings {
-				if searchConditionKeys.clinicNameSearchPhase == clinicNameSubStrings[j] {
-					isSearchConditionMatched = true
-				}
-			}
-		}
-
-		// Search condition check for state
-		if searchConditionKeys.stateSearchPhase == strings.ToLower(clinicsData[i].State) {
-			isSearchConditionMatched = true
-		}
-
-		// Search condition check for time
 
-		// convert time availability data from string to time format
-		availableFrom, _ := time.Parse("15:04", clinicsData[i].Availablity.From)
-		availableTo, _ := time.Parse("15:04", clinicsData[i].Availablity.To)
-
-		if searchConditionKeys.timeFromStr != "" && searchConditionKeys.timeToStr != "" {
-			if availableFrom.After(searchConditionKeys.timeFrom) &&
-				availableTo.Before(searchConditionKeys.timeTo) {
-				isSearchConditionMatched = true
-			}
-		} else if searchConditionKeys.timeFromStr != "" {
-			if availableFrom.After(searchConditionKeys.timeFrom) {
-				isSearchConditionMatched = true
-			}
-		} else if searchConditionKeys.timeToStr != "" {
-			if availableTo.Before(searchConditionKeys.timeTo) {
-				isSearchConditionMatched = true
-			}
-		}
-
-		if isSearchConditionMatched {
-			filteredData = append(filteredData, clinicsData[i])
-		}
-	}
-	return filteredData
+	return responseData, nil
 }
@@ -0,0 +1,174 @@
+package clinics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types of CSV and NDJSON responses
+const (
+	csvMediaType    = "text/csv"
+	ndjsonMediaType = "application/x-ndjson"
+)
+
+// Number of NDJSON lines sent to the client at once
+const ndjsonFlushLines = 100
+
+// formatMediaTypes are the values of the format query param and their media types
+var formatMediaTypes = map[string]string{
+	"json":    jsonMediaType,
+	"geojson": geoJSONMediaType,
+	"csv":     csvMediaType,
+	"ndjson":  ndjsonMediaType,
+}
+
+// Columns of CSV responses, the same for every clinic type
+var csvHeader = []string{
+	"name", "state", "stateCode", "type", "availability", "weeklyHours", "closures",
+	"lat", "lng", "approximateLocation", "score", "distanceKm",
+}
+
+/* [responseMediaType] - Media type of the response to a clinic search. The format query param,
+one of json, geojson, csv or ndjson, takes precedence over the Accept header. CSV and NDJSON have
+no place for facets, so they can not be asked for together.*/
+
+func responseMediaType(r *http.Request) (string, error) {
+	queryParams := r.URL.Query()
+	mediaType := negotiateMediaType(r, jsonMediaType, geoJSONMediaType, csvMediaType, ndjsonMediaType)
+	if keys, ok := queryParams["format"]; ok {
+		if mediaType, ok = formatMediaTypes[strings.ToLower(keys[0])]; !ok {
+			return "", errors.New("Please provide format as json, geojson, csv or ndjson.")
+		}
+	}
+
+	if _, ok := queryParams["facets"]; ok && (mediaType == csvMediaType || mediaType == ndjsonMediaType) {
+		return "", errors.New("Please provide facets only with the json or geojson format.")
+	}
+	return mediaType, nil
+}
+
+/* [writeExportHeaders] - Set the paging metadata of ResponseData as headers, for the formats
+without an envelope.*/
+
+func writeExportHeaders(w http.ResponseWriter, meta searchMeta) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(meta.total))
+	if meta.hasMore {
+		w.Header().Set("X-Next-Cursor", meta.nextCursor)
+	}
+	if len(meta.unavailableSources) > 0 {
+		w.Header().Set("X-Unavailable-Sources", strings.Join(meta.unavailableSources, ","))
+	}
+}
+
+/* [writeCSVResponse] - Write a page of clinics as CSV with a header row. Known clinic types are
+converted into the common clinic model first, so every type has the same columns. Text starting
+like a spreadsheet formula is prefixed with a quote, so it is not run when the file is opened.*/
+
+func writeCSVResponse(w http.ResponseWriter, clinics []Clinic, meta searchMeta, statusCode int) {
+	w.Header().Set("Content-Type", csvMediaType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="clinics.csv"`)
+	writeExportHeaders(w, meta)
+	w.WriteHeader(statusCode)
+
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, clinic := range clinics {
+		writer.Write(clinicCSVRecord(clinic))
+	}
+	writer.Flush()
+}
+
+func clinicCSVRecord(clinic Clinic) []string {
+	clinicData, _ := toClinicInfo(clinic)
+
+	record := []string{
+		csvText(clinic.ClinicName()),
+		csvText(clinic.ClinicState()),
+		clinic.ClinicStateCode(),
+		clinic.ClinicType(),
+		csvText(formatOpeningHours(clinicData.Availablity)),
+		csvText(formatWeeklyHours(clinicData.WeeklyHours)),
+		csvText(strings.Join(clinicData.Closures, " ")),
+		"", "",
+		strconv.FormatBool(clinicData.ApproximateLocation),
+		"", "",
+	}
+	if location, ok := clinic.coordinates(); ok {
+		record[7] = strconv.FormatFloat(location.lat, 'f', -1, 64)
+		record[8] = strconv.FormatFloat(location.lng, 'f', -1, 64)
+	}
+	if clinic.ClinicScore() != 0 {
+		record[10] = strconv.FormatFloat(clinic.ClinicScore(), 'f', -1, 64)
+	}
+	if distance, ok := clinic.distance(); ok {
+		record[11] = strconv.FormatFloat(distance, 'f', -1, 64)
+	}
+	return record
+}
+
+// csvText keeps spreadsheets from reading text as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatOpeningHours writes opening hours as space separated intervals, e.g. "08:00-12:00 13:00-18:00"
+func formatOpeningHours(hours openingHours) string {
+	intervals := make([]string, 0, len(hours))
+	for _, interval := range hours {
+		intervals = append(intervals, interval.From+"-"+interval.To)
+	}
+	return strings.Join(intervals, " ")
+}
+
+// formatWeeklyHours writes weekly hours in weekday order, e.g. "sat 09:00-13:00; sun closed"
+func formatWeeklyHours(weekly weeklyHours) string {
+	days := make([]string, 0, len(weekly))
+	for day := range weekly {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		dayI, dayJ := weekdaysByKey[strings.ToLower(days[i])], weekdaysByKey[strings.ToLower(days[j])]
+		if dayI != dayJ {
+			return dayI < dayJ
+		}
+		return days[i] < days[j]
+	})
+
+	dayHours := make([]string, 0, len(days))
+	for _, day := range days {
+		hours := formatOpeningHours(weekly[day])
+		if hours == "" {
+			hours = "closed"
+		}
+		dayHours = append(dayHours, day+" "+hours)
+	}
+	return strings.Join(dayHours, "; ")
+}
+
+/* [writeNDJSONResponse] - Stream a page of clinics as newline delimited JSON, one clinic per line
+in the same form as in ResponseData. Lines are sent to the client as they are written.*/
+
+func writeNDJSONResponse(w http.ResponseWriter, clinics []Clinic, meta searchMeta, statusCode int) {
+	w.Header().Set("Content-Type", ndjsonMediaType)
+	writeExportHeaders(w, meta)
+	w.WriteHeader(statusCode)
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for i, clinic := range clinics {
+		if err := encoder.Encode(clinic); err != nil {
+			return
+		}
+		if flusher != nil && (i+1)%ndjsonFlushLines == 0 {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package clinics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestResponseMediaType(t *testing.T) {
+	tests := []struct {
+		query     string
+		accept    string
+		expected  string
+		expectErr bool
+	}{
+		{"", "", jsonMediaType, false},
+		{"", "*/*", jsonMediaType, false},
+		{"", "text/*", csvMediaType, false},
+		{"", "text/csv", csvMediaType, false},
+		{"", "application/x-ndjson", ndjsonMediaType, false},
+		{"", "application/geo+json", geoJSONMediaType, false},
+		{"", "text/html", jsonMediaType, false},
+
+		// q-values
+		{"", "text/csv;q=0.5, application/geo+json", geoJSONMediaType, false},
+		{"", "text/csv;q=0.9, application/geo+json;q=0.8", csvMediaType, false},
+		{"", "application/json;q=0.1, */*;q=0.5", geoJSONMediaType, false},
+		{"", "text/*;q=0.2, text/csv;q=0.9, application/*;q=0.5", csvMediaType, false},
+		{"", "text/csv;q=0, application/geo+json;q=0", jsonMediaType, false},
+		{"", "text/csv; Q=0.2, application/x-ndjson", ndjsonMediaType, false},
+
+		// the format query param takes precedence over Accept
+		{"format=csv", "application/geo+json", csvMediaType, false},
+		{"format=NDJSON", "text/csv", ndjsonMediaType, false},
+		{"format=json", "text/*", jsonMediaType, false},
+		{"format=xml", "", "", true},
+
+		// CSV and NDJSON have no place for facets
+		{"facets=state", "", jsonMediaType, false},
+		{"facets=state&format=geojson", "", geoJSONMediaType, false},
+		{"facets=state&format=csv", "", "", true},
+		{"facets=state", "application/x-ndjson", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query+" "+test.accept, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/clinics/search?"+test.query, nil)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+
+			mediaType, err := responseMediaType(r)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("responseMediaType() = %q, want an error", mediaType)
+				}
+				return
+			}
+			if err != nil || mediaType != test.expected {
+				t.Errorf("responseMediaType() = %q, %v, want %q", mediaType, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestAcceptQuality(t *testing.T) {
+	tests := []struct {
+		accepts   []string
+		mediaType string
+		expected  float64
+	}{
+		{nil, csvMediaType, -1},
+		{[]string{"application/json"}, csvMediaType, -1},
+		{[]string{"*/*"}, csvMediaType, 1},
+		{[]string{"text/*;q=0.4"}, csvMediaType, 0.4},
+		// the most specific range counts, wherever it is listed
+		{[]string{"text/csv;q=0.7, text/*;q=0.2, */*;q=0.1"}, csvMediaType, 0.7},
+		{[]string{"*/*;q=0.1", "text/*;q=0.3"}, csvMediaType, 0.3},
+		{[]string{"TEXT/CSV;q=0.6"}, csvMediaType, 0.6},
+		{[]string{"text/csv;q=invalid"}, csvMediaType, 1},
+		{[]string{"text"}, csvMediaType, -1},
+	}
+
+	for _, test := range tests {
+		if quality := acceptQuality(test.accepts, test.mediaType); quality != test.expected {
+			t.Errorf("acceptQuality(%q, %s) = %v, want %v", test.accepts, test.mediaType, quality, test.expected)
+		}
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"Mayo Clinic", "Mayo Clinic"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1+2", "'+1+2"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"Clinic =1", "Clinic =1"},
+	}
+
+	for _, test := range tests {
+		if value := csvText(test.value); value != test.expected {
+			t.Errorf("csvText(%q) = %q, want %q", test.value, value, test.expected)
+		}
+	}
+}
+
+func TestWriteCSVResponseNeutralizesFormulas(t *testing.T) {
+	clinics := []Clinic{clinicInfo{
+		Name:      "=cmd|' /C calc'!A0",
+		State:     "@California",
+		StateCode: "CA",
+		Type:      dentalClinicType,
+		Closures:  []string{"-2024-12-25"},
+	}}
+
+	w := httptest.NewRecorder()
+	writeCSVResponse(w, clinics, searchMeta{total: 1}, 200)
+	records, err := csv.NewReader(bytes.NewReader(w.Body.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the CSV response: %v", err)
+	}
+	if len(records) != 2 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("CSV response = %q, want the header and one clinic", records)
+	}
+
+	record := records[1]
+	if record[0] != "'=cmd|' /C calc'!A0" || record[1] != "'@California" || record[6] != "'-2024-12-25" {
+		t.Errorf("CSV record = %q, want the name, state and closures prefixed with a quote", record)
+	}
+	if record[2] != "CA" || record[3] != dentalClinicType {
+		t.Errorf("CSV record = %q, want the state code and type as they are", record)
+	}
+}
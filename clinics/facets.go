@@ -0,0 +1,105 @@
+package clinics
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Fields search results can be counted by
+var facetFields = []string{"state", "openingHour", "type"}
+
+// facetCount is how many of the searched clinics have a value of a facet
+type facetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+/* [validateFacetParams] - Validate the facets query param, a comma separated list of the fields to
+count the searched clinics by, e.g. facets=state,type.*/
+
+func validateFacetParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	keys, ok := queryParamValues(queryParams, "facets")
+	if !ok {
+		return nil
+	}
+
+	for _, key := range keys {
+		if !isFacetField(key) {
+			keys = nil
+			break
+		}
+	}
+	if keys == nil {
+		return errors.New("Please provide facets as any of " + strings.Join(facetFields, ", ") + ".")
+	}
+
+	counted := make(map[string]bool)
+	for _, key := range keys {
+		if !counted[key] {
+			counted[key] = true
+			searchConditionKeys.facets = append(searchConditionKeys.facets, key)
+		}
+	}
+	return nil
+}
+
+func isFacetField(field string) bool {
+	for i := range facetFields {
+		if facetFields[i] == field {
+			return true
+		}
+	}
+	return false
+}
+
+/* [countFacets] - Count the searched clinics by every requested facet, before they are paged.
+	a) state - the state code, or the state as given when it is unknown
+	b) openingHour - the hour the clinic opens earliest, e.g. "08:00" for 08:30, or "none"
+	c) type - dental or vet
+Values are ordered by count, the opening hours by time.*/
+
+func countFacets(clinics []Clinic, searchConditionKeys searchConditions) map[string][]facetCount {
+	facets := make(map[string][]facetCount, len(searchConditionKeys.facets))
+
+	for _, field := range searchConditionKeys.facets {
+		counts := make(map[string]int)
+		for _, clinic := range clinics {
+			counts[facetValue(clinic, field, searchConditionKeys)]++
+		}
+
+		facet := make([]facetCount, 0, len(counts))
+		for value, count := range counts {
+			facet = append(facet, facetCount{Value: value, Count: count})
+		}
+		sort.Slice(facet, func(i, j int) bool {
+			if field != "openingHour" && facet[i].Count != facet[j].Count {
+				return facet[i].Count > facet[j].Count
+			}
+			return facet[i].Value < facet[j].Value
+		})
+		facets[field] = facet
+	}
+	return facets
+}
+
+func facetValue(clinic Clinic, field string, searchConditionKeys searchConditions) string {
+	switch field {
+	case "state":
+		return firstNonEmpty(clinic.ClinicStateCode(), clinic.ClinicState())
+	case "openingHour":
+		intervals := clinic.openingSchedule().intervalsFor(searchConditionKeys)
+		// "none" sorts after the hours
+		if len(intervals) == 0 {
+			return "none"
+		}
+		earliestOpening := intervals[0].start
+		for _, interval := range intervals[1:] {
+			earliestOpening = minInt(earliestOpening, interval.start)
+		}
+		return fmt.Sprintf("%02d:00", earliestOpening/60)
+	}
+	return clinic.ClinicType()
+}
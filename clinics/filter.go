@@ -0,0 +1,463 @@
+package clinics
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/* The filter query param is a boolean expression over the fields of a clinic, e.g.
+	filter=(state=CA OR state=NV) AND clinicName=mayo
+	filter=NOT type=vet AND openFrom<=09:00
+	filter=state in (CA, "New York") AND openTo>20:00
+AND binds tighter than OR, keywords are case insensitive and values with spaces or operator
+characters are quoted with " or '. Fields and their operators:
+	a) name, clinicName - = (whole words of the name, like the clinicName param), !=, ~ (part of
+	   the name), in
+	b) state - = (name or code), !=, ~ (part of the name), in
+	c) type - =, !=, in with dental or vet
+	d) openFrom, openTo - =, !=, <, <=, >, >= with a time, compared against the opening and
+	   closing time of each interval of the clinic; any interval may match. Overnight intervals
+	   close on the next day, e.g. 22:00 to 06:00 matches openTo<=07:00, and 24:00 is the end of
+	   the day*/
+
+// filterClinic is the view of a clinic that filter expressions are evaluated against
+type filterClinic struct {
+	name       string
+	state      string
+	stateCode  string
+	clinicType string
+	intervals  []timeInterval
+}
+
+// newFilterClinic builds the filter view of a clinic, with the opening hours of the searched day
+func newFilterClinic(clinic Clinic, searchConditionKeys searchConditions) filterClinic {
+	return filterClinic{
+		name:       clinic.ClinicName(),
+		state:      clinic.ClinicState(),
+		stateCode:  clinic.ClinicStateCode(),
+		clinicType: clinic.ClinicType(),
+		intervals:  clinic.openingSchedule().intervalsFor(searchConditionKeys),
+	}
+}
+
+// filterExpr is a node of a parsed filter expression
+type filterExpr interface {
+	eval(clinic filterClinic) bool
+}
+
+type andFilter struct {
+	left, right filterExpr
+}
+
+type orFilter struct {
+	left, right filterExpr
+}
+
+type notFilter struct {
+	expr filterExpr
+}
+
+// comparisonFilter compares a field of the clinic against one or more values
+type comparisonFilter struct {
+	field   string
+	op      string
+	values  []string
+	minutes []int
+}
+
+func (expr andFilter) eval(clinic filterClinic) bool {
+	return expr.left.eval(clinic) && expr.right.eval(clinic)
+}
+
+func (expr orFilter) eval(clinic filterClinic) bool {
+	return expr.left.eval(clinic) || expr.right.eval(clinic)
+}
+
+func (expr notFilter) eval(clinic filterClinic) bool {
+	return !expr.expr.eval(clinic)
+}
+
+func (expr comparisonFilter) eval(clinic filterClinic) bool {
+	switch expr.field {
+	case "name":
+		return expr.matchText(strings.ToLower(clinic.name), func(value string) bool {
+			matched, _ := matchClinicNamePhase(clinic.name, value, searchConditions{nameMatchMode: nameMatchWord})
+			return matched
+		})
+	case "state":
+		return expr.matchText(strings.ToLower(clinic.state), func(value string) bool {
+			return matchClinicState(clinic.stateCode, clinic.state, stateSearchConditions(value))
+		})
+	case "type":
+		return expr.matchText(clinic.clinicType, func(value string) bool {
+			return clinic.clinicType == value
+		})
+	}
+
+	for _, interval := range clinic.intervals {
+		clinicTime := interval.start
+		if expr.field == "openTo" {
+			// closing times past midnight are on the next day, a closing time of 24:00 is kept
+			clinicTime = interval.end
+			if clinicTime > minutesPerDay {
+				clinicTime -= minutesPerDay
+			}
+		}
+		if compareMinutes(clinicTime, expr.op, expr.minutes[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchText evaluates the text operators, equals checks a single value against the field
+func (expr comparisonFilter) matchText(fieldValue string, equals func(value string) bool) bool {
+	switch expr.op {
+	case "~":
+		return strings.Contains(fieldValue, expr.values[0])
+	case "!=":
+		return !equals(expr.values[0])
+	}
+
+	// = and in
+	for _, value := range expr.values {
+		if equals(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareMinutes(clinicTime int, op string, filterTime int) bool {
+	switch op {
+	case "=":
+		return clinicTime == filterTime
+	case "!=":
+		return clinicTime != filterTime
+	case "<":
+		return clinicTime < filterTime
+	case "<=":
+		return clinicTime <= filterTime
+	case ">":
+		return clinicTime > filterTime
+	case ">=":
+		return clinicTime >= filterTime
+	}
+	return false
+}
+
+// stateSearchConditions builds the state search conditions matchClinicState compares against
+func stateSearchConditions(value string) searchConditions {
+	stateSearchCode := ""
+	if state, ok := normalizeState(value); ok {
+		stateSearchCode = state.code
+	}
+	return searchConditions{stateSearchPhases: []string{value}, stateSearchCodes: []string{stateSearchCode}}
+}
+
+// Operators allowed per field, fields are found by their lower case name
+var filterFieldOps = map[string][]string{
+	"name":     {"=", "!=", "~", "in"},
+	"state":    {"=", "!=", "~", "in"},
+	"type":     {"=", "!=", "in"},
+	"openFrom": {"=", "!=", "<", "<=", ">", ">="},
+	"openTo":   {"=", "!=", "<", "<=", ">", ">="},
+}
+
+var filterFieldsByKey = map[string]string{
+	"name":       "name",
+	"clinicname": "name",
+	"state":      "state",
+	"type":       "type",
+	"openfrom":   "openFrom",
+	"opento":     "openTo",
+}
+
+/* [validateFilterParams] - Validate the filter query param and parse it into an expression. The
+filter applies on top of the other search conditions.*/
+
+func validateFilterParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	keys, ok := queryParams["filter"]
+	if !ok {
+		return nil
+	}
+	if strings.TrimSpace(keys[0]) == "" {
+		return errors.New("Please provide filter for search.")
+	}
+
+	filter, err := parseFilter(keys[0])
+	if err != nil {
+		return err
+	}
+	searchConditionKeys.filter = filter
+	return nil
+}
+
+// filterSyntaxError is an invalid filter expression, pos is the 1-based character it was found at
+type filterSyntaxError struct {
+	pos     int
+	message string
+}
+
+func (err *filterSyntaxError) Error() string {
+	return fmt.Sprintf("Invalid filter at position %d: %s.", err.pos, err.message)
+}
+
+// Kinds of filter tokens
+const (
+	filterTokenEOF = iota
+	filterTokenWord
+	filterTokenString
+	filterTokenOp
+	filterTokenLParen
+	filterTokenRParen
+	filterTokenComma
+)
+
+type filterToken struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// isKeyword checks if the token is the unquoted keyword, ignoring case
+func (token filterToken) isKeyword(keyword string) bool {
+	return token.kind == filterTokenWord && strings.EqualFold(token.value, keyword)
+}
+
+func (token filterToken) describe() string {
+	if token.kind == filterTokenEOF {
+		return "end of filter"
+	}
+	return fmt.Sprintf("%q", token.value)
+}
+
+/* [tokenizeFilter] - Split a filter expression into words, quoted strings, operators, parentheses
+and commas.*/
+
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenLParen, value: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenRParen, value: ")", pos: pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenComma, value: ",", pos: pos})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &filterSyntaxError{pos: pos, message: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, value: string(runes[i+1 : end]), pos: pos})
+			i = end + 1
+		case r == '=' || r == '~':
+			tokens = append(tokens, filterToken{kind: filterTokenOp, value: string(r), pos: pos})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			} else if r == '!' {
+				return nil, &filterSyntaxError{pos: pos, message: `expected "!="`}
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOp, value: op, pos: pos})
+			i += len(op)
+		default:
+			end := i
+			for end < len(runes) && !strings.ContainsRune(" \t\n\r(),\"'=~!<>", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenWord, value: string(runes[i:end]), pos: pos})
+			i = end
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEOF, pos: len(runes) + 1}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	next   int
+}
+
+/* [parseFilter] - Parse a filter expression into its syntax tree:
+	expression := and { OR and }
+	and        := not { AND not }
+	not        := NOT not | "(" expression ")" | comparison
+	comparison := field op value | field IN "(" value { "," value } ")"*/
+
+func parseFilter(input string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != filterTokenEOF {
+		return nil, &filterSyntaxError{pos: token.pos, message: "unexpected " + token.describe()}
+	}
+	return expr, nil
+}
+
+func (parser *filterParser) peek() filterToken {
+	return parser.tokens[parser.next]
+}
+
+func (parser *filterParser) advance() filterToken {
+	token := parser.tokens[parser.next]
+	if token.kind != filterTokenEOF {
+		parser.next++
+	}
+	return token
+}
+
+func (parser *filterParser) parseOr() (filterExpr, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek().isKeyword("or") {
+		parser.advance()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *filterParser) parseAnd() (filterExpr, error) {
+	left, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek().isKeyword("and") {
+		parser.advance()
+		right, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *filterParser) parseNot() (filterExpr, error) {
+	token := parser.peek()
+	switch {
+	case token.isKeyword("not"):
+		parser.advance()
+		expr, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{expr: expr}, nil
+	case token.kind == filterTokenLParen:
+		parser.advance()
+		expr, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := parser.advance(); closing.kind != filterTokenRParen {
+			return nil, &filterSyntaxError{pos: closing.pos, message: `expected ")" but found ` + closing.describe()}
+		}
+		return expr, nil
+	}
+	return parser.parseComparison()
+}
+
+func (parser *filterParser) parseComparison() (filterExpr, error) {
+	fieldToken := parser.advance()
+	field, ok := filterFieldsByKey[strings.ToLower(fieldToken.value)]
+	if fieldToken.kind != filterTokenWord || !ok {
+		return nil, &filterSyntaxError{pos: fieldToken.pos, message: "expected a field but found " + fieldToken.describe()}
+	}
+
+	opToken := parser.advance()
+	op := opToken.value
+	if opToken.isKeyword("in") {
+		op = "in"
+	} else if opToken.kind != filterTokenOp {
+		return nil, &filterSyntaxError{pos: opToken.pos, message: "expected an operator but found " + opToken.describe()}
+	}
+	if !isFilterFieldOp(field, op) {
+		return nil, &filterSyntaxError{pos: opToken.pos, message: fmt.Sprintf("operator %q can not be used with %s", op, field)}
+	}
+
+	expr := comparisonFilter{field: field, op: op}
+	if op == "in" {
+		if token := parser.advance(); token.kind != filterTokenLParen {
+			return nil, &filterSyntaxError{pos: token.pos, message: `expected "(" but found ` + token.describe()}
+		}
+		for {
+			if err := parser.parseValue(&expr); err != nil {
+				return nil, err
+			}
+			token := parser.advance()
+			if token.kind == filterTokenRParen {
+				break
+			}
+			if token.kind != filterTokenComma {
+				return nil, &filterSyntaxError{pos: token.pos, message: `expected "," or ")" but found ` + token.describe()}
+			}
+		}
+		return expr, nil
+	}
+
+	if err := parser.parseValue(&expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseValue adds the next value to the comparison, converting times of the time fields to minutes
+func (parser *filterParser) parseValue(expr *comparisonFilter) error {
+	token := parser.advance()
+	if token.kind != filterTokenWord && token.kind != filterTokenString {
+		return &filterSyntaxError{pos: token.pos, message: "expected a value but found " + token.describe()}
+	}
+
+	switch expr.field {
+	case "openFrom", "openTo":
+		minutes, err := parseClockTime(token.value)
+		if err != nil {
+			return &filterSyntaxError{pos: token.pos, message: "expected a time in hour and minute format but found " + token.describe()}
+		}
+		expr.minutes = append(expr.minutes, minutes)
+	case "type":
+		if value := strings.ToLower(token.value); value != dentalClinicType && value != vetClinicType {
+			return &filterSyntaxError{pos: token.pos, message: "expected dental or vet but found " + token.describe()}
+		}
+	}
+	expr.values = append(expr.values, strings.ToLower(token.value))
+	return nil
+}
+
+func isFilterFieldOp(field string, op string) bool {
+	for _, fieldOp := range filterFieldOps[field] {
+		if fieldOp == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package clinics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{"state=CA AND", "Invalid filter at position 13: expected a field but found end of filter."},
+		{"(state=CA", `Invalid filter at position 10: expected ")" but found end of filter.`},
+		{"state==CA", `Invalid filter at position 7: expected a value but found "=".`},
+		{"state=", "Invalid filter at position 7: expected a value but found end of filter."},
+		{"foo=bar", `Invalid filter at position 1: expected a field but found "foo".`},
+		{"state in CA", `Invalid filter at position 10: expected "(" but found "CA".`},
+		{"state in (CA NV)", `Invalid filter at position 14: expected "," or ")" but found "NV".`},
+		{"openFrom<=9am", `Invalid filter at position 11: expected a time in hour and minute format but found "9am".`},
+		{"type=cat", `Invalid filter at position 6: expected dental or vet but found "cat".`},
+		{`name~"mayo`, "Invalid filter at position 6: unterminated string."},
+		{"state=CA)", `Invalid filter at position 9: unexpected ")".`},
+		{"name<mayo", `Invalid filter at position 5: operator "<" can not be used with name.`},
+		{"state=CA OR OR", `Invalid filter at position 13: expected a field but found "OR".`},
+		{"state ! CA", `Invalid filter at position 7: expected "!=".`},
+		{"NOT", "Invalid filter at position 4: expected a field but found end of filter."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			_, err := parseFilter(test.filter)
+			if err == nil {
+				t.Fatalf("parseFilter(%q) error = nil, want %q", test.filter, test.expected)
+			}
+			if err.Error() != test.expected {
+				t.Errorf("parseFilter(%q) error = %q, want %q", test.filter, err.Error(), test.expected)
+			}
+		})
+	}
+}
+
+func TestFilterClinicsByExpression(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{"(state=FL OR state=NV) AND clinicName=mayo", []string{"dental: Mayo Clinic"}},
+		{"clinicName=clinic", []string{
+			"dental: Mayo Clinic", "dental: Cleveland Clinic", "vet: National Veterinary Clinic", "vet: City Vet Clinic",
+		}},
+		{`name="mayo clinic"`, []string{"dental: Mayo Clinic"}},
+		{"name=may", []string{}},
+		{"name~may", []string{"dental: Mayo Clinic"}},
+		{"openTo<=07:00", []string{"vet: Night Owl Animal Hospital"}},
+		{"type=vet AND NOT state in (CA, NV)", []string{"vet: Good Health Home", "vet: German Pets Clinics"}},
+		{"state=california and openFrom<12:00", []string{"dental: Scratchpay Test Pet Medical Center", "vet: Scratchpay Test Pet Medical Center"}},
+	}
+
+	clinics := fixtureClinics(t)
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			expr, err := parseFilter(test.filter)
+			if err != nil {
+				t.Fatalf("parseFilter(%q) error = %v", test.filter, err)
+			}
+
+			result := filterClinicsByExpression(clinics, searchConditions{filter: expr})
+			if names := clinicNames(result); !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("filter %q = %q, want %q", test.filter, names, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package clinics
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: how fast term frequency saturates and how much the document length counts
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopWords are left out of both the indexed text and the queries
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "by": true, "for": true, "from": true,
+	"in": true, "of": true, "on": true, "s": true, "the": true, "to": true, "with": true,
+}
+
+/* [tokenizeText] - Split text into lower case words on anything that is not a letter or a digit,
+leaving out stop words and stemming the rest.*/
+
+func tokenizeText(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, stemWord(word))
+	}
+	return tokens
+}
+
+/* [stemWord] - Reduce a word to its stem by removing common English suffixes, so that e.g.
+clinics matches clinic and hospitals matches hospital. Short words are kept as they are.*/
+
+func stemWord(word string) string {
+	runeCount := len([]rune(word))
+	switch {
+	case runeCount > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"):
+		return strings.TrimSuffix(word, "es")
+	case runeCount > 5 && strings.HasSuffix(word, "ing"):
+		return strings.TrimSuffix(word, "ing")
+	case runeCount > 4 && strings.HasSuffix(word, "ed"):
+		return strings.TrimSuffix(word, "ed")
+	case runeCount > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// clinicText is the text of a clinic that q searches, its name and state
+func clinicText(clinic Clinic) string {
+	return clinic.ClinicName() + " " + clinic.ClinicState() + " " + clinic.ClinicStateCode()
+}
+
+/* [validateFullTextParams] - Validate the q query param, a full-text query over the name and state
+of the clinics. Its words are tokenized like the clinic text, so a query of only stop words has
+nothing to search for.*/
+
+func validateFullTextParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	keys, ok := queryParams["q"]
+	if !ok {
+		return nil
+	}
+
+	searchConditionKeys.textTerms = tokenizeText(keys[0])
+	if len(searchConditionKeys.textTerms) == 0 {
+		return errors.New("Please provide q with words to search for.")
+	}
+	return nil
+}
+
+/* [textScores] - Rank the clinics containing any of the terms with BM25 over their name and state.
+It returns the positions of those clinics, ascending, and the scores by position. Scores are
+divided by the best score, so the best matching clinic scores 1.*/
+
+func (index *clinicIndex) textScores(terms []string) ([]int, []float64) {
+	scores := make([]float64, len(index.clinics))
+	termPostings := make([][]int, 0, len(terms))
+	clinicCount := float64(len(index.clinics))
+
+	for _, term := range terms {
+		postings, termFrequencies := index.textPostings[term], index.textTermFrequencies[term]
+		if len(postings) == 0 {
+			continue
+		}
+		termPostings = append(termPostings, postings)
+		idf := math.Log(1 + (clinicCount-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+		for i, position := range postings {
+			termFrequency := float64(termFrequencies[i])
+			lengthRatio := float64(index.textLengths[position]) / index.averageTextLength
+			scores[position] += idf * termFrequency * (bm25K1 + 1) /
+				(termFrequency + bm25K1*(1-bm25B+bm25B*lengthRatio))
+		}
+	}
+
+	positions := unionPositions(termPostings...)
+	bestScore := 0.0
+	for _, position := range positions {
+		bestScore = math.Max(bestScore, scores[position])
+	}
+	for _, position := range positions {
+		scores[position] /= bestScore
+	}
+	return positions, scores
+}
@@ -0,0 +1,194 @@
+package clinics
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/* geoArea is an area of the map clinics are searched in, e.g. the bounding box of a map view or a
+polygon drawn on it.*/
+type geoArea interface {
+	// bounds are the boxes covering the area, for looking clinics up in the spatial index
+	bounds() []geoBox
+	// contains checks if a location is inside the area
+	contains(location geoPoint) bool
+}
+
+// geoBox is a box between two longitudes and two latitudes, minLng is west of maxLng
+type geoBox struct {
+	minLng float64
+	minLat float64
+	maxLng float64
+	maxLat float64
+}
+
+// boundingBox is the box of the bbox query param, it can cross the antimeridian
+type boundingBox geoBox
+
+func (box boundingBox) bounds() []geoBox {
+	if box.minLng <= box.maxLng {
+		return []geoBox{geoBox(box)}
+	}
+	return []geoBox{
+		{minLng: box.minLng, minLat: box.minLat, maxLng: 180, maxLat: box.maxLat},
+		{minLng: -180, minLat: box.minLat, maxLng: box.maxLng, maxLat: box.maxLat},
+	}
+}
+
+func (box boundingBox) contains(location geoPoint) bool {
+	if location.lat < box.minLat || location.lat > box.maxLat {
+		return false
+	}
+	if box.minLng <= box.maxLng {
+		return location.lng >= box.minLng && location.lng <= box.maxLng
+	}
+	return location.lng >= box.minLng || location.lng <= box.maxLng
+}
+
+/* geoPolygons are the polygons of a GeoJSON Polygon or MultiPolygon. The first ring of a polygon is
+its outline and the others are holes in it. Edges are straight lines between the positions on the
+map.*/
+type geoPolygons [][][]geoPoint
+
+func (polygons geoPolygons) bounds() []geoBox {
+	boxes := make([]geoBox, 0, len(polygons))
+	for _, rings := range polygons {
+		box := geoBox{minLng: 180, minLat: 90, maxLng: -180, maxLat: -90}
+		for _, position := range rings[0] {
+			box.minLng = math.Min(box.minLng, position.lng)
+			box.minLat = math.Min(box.minLat, position.lat)
+			box.maxLng = math.Max(box.maxLng, position.lng)
+			box.maxLat = math.Max(box.maxLat, position.lat)
+		}
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+/* [contains] - Check if a location is inside any of the polygons, by counting how many edges of
+the rings a ray from the location crosses. Inside a hole the count is even again.*/
+
+func (polygons geoPolygons) contains(location geoPoint) bool {
+	for _, rings := range polygons {
+		inside := false
+		for _, ring := range rings {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				a, b := ring[i], ring[j]
+				if (a.lat > location.lat) != (b.lat > location.lat) &&
+					location.lng < (b.lng-a.lng)*(location.lat-a.lat)/(b.lat-a.lat)+a.lng {
+					inside = !inside
+				}
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+/* [validateAreaParams] - Validate the bbox and polygon query params, the areas of the map to
+search clinics in. bbox is minLng,minLat,maxLng,maxLat, with minLng greater than maxLng for boxes
+across the antimeridian. polygon is a GeoJSON Polygon or MultiPolygon geometry. Clinics have to be
+inside every given area.*/
+
+func validateAreaParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	if keys, ok := queryParams["bbox"]; ok {
+		box, err := parseBoundingBox(keys[0])
+		if err != nil {
+			return err
+		}
+		searchConditionKeys.areas = append(searchConditionKeys.areas, box)
+	}
+
+	if keys, ok := queryParams["polygon"]; ok {
+		polygons, err := parsePolygons(keys[0])
+		if err != nil {
+			return err
+		}
+		searchConditionKeys.areas = append(searchConditionKeys.areas, polygons)
+	}
+	return nil
+}
+
+func parseBoundingBox(bboxStr string) (boundingBox, error) {
+	err := errors.New("Please provide bbox as minLng,minLat,maxLng,maxLat in decimal degrees.")
+
+	values := strings.Split(bboxStr, ",")
+	if len(values) != 4 {
+		return boundingBox{}, err
+	}
+	var numbers [4]float64
+	for i := range values {
+		number, parseErr := strconv.ParseFloat(strings.TrimSpace(values[i]), 64)
+		if parseErr != nil {
+			return boundingBox{}, err
+		}
+		numbers[i] = number
+	}
+
+	box := boundingBox{minLng: numbers[0], minLat: numbers[1], maxLng: numbers[2], maxLat: numbers[3]}
+	if !isValidGeoPoint(box.minLat, box.minLng) || !isValidGeoPoint(box.maxLat, box.maxLng) || box.minLat > box.maxLat {
+		return boundingBox{}, err
+	}
+	return box, nil
+}
+
+/* [parsePolygons] - Parse a GeoJSON Polygon or MultiPolygon geometry. Positions are
+[longitude, latitude] and every ring has at least 4 of them, the last one equal to the first.*/
+
+func parsePolygons(polygonStr string) (geoPolygons, error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal([]byte(polygonStr), &geometry); err != nil {
+		return nil, errors.New("Please provide polygon as a GeoJSON Polygon or MultiPolygon geometry.")
+	}
+
+	var coordinates [][][][]float64
+	var err error
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		err = json.Unmarshal(geometry.Coordinates, &polygon)
+		coordinates = [][][][]float64{polygon}
+	case "MultiPolygon":
+		err = json.Unmarshal(geometry.Coordinates, &coordinates)
+	default:
+		return nil, errors.New("Please provide polygon as a GeoJSON Polygon or MultiPolygon geometry.")
+	}
+	if err != nil || len(coordinates) == 0 {
+		return nil, errors.New("Please provide the coordinates of polygon as GeoJSON positions.")
+	}
+
+	polygons := make(geoPolygons, 0, len(coordinates))
+	for _, polygon := range coordinates {
+		if len(polygon) == 0 {
+			return nil, errors.New("Please provide the coordinates of polygon as GeoJSON positions.")
+		}
+		rings := make([][]geoPoint, 0, len(polygon))
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, errors.New("Please provide polygon rings with at least 4 positions, the last one equal to the first.")
+			}
+			positions := make([]geoPoint, 0, len(ring))
+			for _, position := range ring {
+				if len(position) < 2 || !isValidGeoPoint(position[1], position[0]) {
+					return nil, errors.New("Please provide polygon positions as [longitude, latitude] in decimal degrees.")
+				}
+				positions = append(positions, geoPoint{lat: position[1], lng: position[0]})
+			}
+			if positions[0] != positions[len(positions)-1] {
+				return nil, errors.New("Please provide polygon rings with at least 4 positions, the last one equal to the first.")
+			}
+			rings = append(rings, positions)
+		}
+		polygons = append(polygons, rings)
+	}
+	return polygons, nil
+}
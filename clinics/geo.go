@@ -0,0 +1,130 @@
+package clinics
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Mean radius of the earth, used for great-circle distances
+const earthRadiusKm = 6371.0088
+
+// geoPoint is a location in decimal degrees
+type geoPoint struct {
+	lat float64
+	lng float64
+}
+
+func isValidGeoPoint(lat float64, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// geoPointOf is the location of the given coordinates, if both are set
+func geoPointOf(lat *float64, lng *float64) (geoPoint, bool) {
+	if lat == nil || lng == nil {
+		return geoPoint{}, false
+	}
+	return geoPoint{lat: *lat, lng: *lng}, true
+}
+
+/* [haversineKm] - Great-circle distance between two locations in kilometers, with the haversine
+formula.*/
+
+func haversineKm(a geoPoint, b geoPoint) float64 {
+	toRadians := math.Pi / 180
+	deltaLat := (b.lat - a.lat) * toRadians
+	deltaLng := (b.lng - a.lng) * toRadians
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(a.lat*toRadians)*math.Cos(b.lat*toRadians)*math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+/* [locateClinic] - Coordinates of a clinic. Clinics without valid coordinates in the source data
+are placed at the center of their state, which is flagged as approximate, and clinics in unknown
+states have no location.*/
+
+func locateClinic(clinicType string, name string, lat *float64, lng *float64, stateCode string) (*float64, *float64, bool) {
+	if location, ok := geoPointOf(lat, lng); ok {
+		if isValidGeoPoint(location.lat, location.lng) {
+			return lat, lng, false
+		}
+		log.Println("Ignoring invalid coordinates of", clinicType, "clinic", name)
+	}
+
+	state, ok := normalizeState(stateCode)
+	if !ok {
+		return nil, nil, false
+	}
+	return &state.latitude, &state.longitude, true
+}
+
+/* [validateGeoParams] - Validate the lat, lng and radiusKm query params. lat and lng are the
+decimal degrees to measure the distance of the clinics from and have to be given together,
+radiusKm keeps only the clinics within that many kilometers of them.*/
+
+func validateGeoParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	latKeys, hasLat := queryParams["lat"]
+	lngKeys, hasLng := queryParams["lng"]
+	radiusKeys, hasRadius := queryParams["radiusKm"]
+
+	if !hasLat && !hasLng {
+		if hasRadius {
+			return errors.New("Please provide lat and lng to search within radiusKm.")
+		}
+		return nil
+	}
+	if !hasLat || !hasLng {
+		return errors.New("Please provide both lat and lng.")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latKeys[0]), 64)
+	if err != nil || !(lat >= -90 && lat <= 90) {
+		return errors.New("Please provide lat as a number between -90 and 90.")
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngKeys[0]), 64)
+	if err != nil || !(lng >= -180 && lng <= 180) {
+		return errors.New("Please provide lng as a number between -180 and 180.")
+	}
+	searchConditionKeys.origin = &geoPoint{lat: lat, lng: lng}
+
+	if hasRadius {
+		radiusKm, err := strconv.ParseFloat(strings.TrimSpace(radiusKeys[0]), 64)
+		if err != nil || !(radiusKm > 0) || math.IsInf(radiusKm, 1) {
+			return errors.New("Please provide radiusKm as a positive number of kilometers.")
+		}
+		searchConditionKeys.radiusKm = radiusKm
+	}
+	return nil
+}
+
+/* [filterClinicsByDistance] - Measure the distance of every clinic from the searched location and
+keep the clinics within the search radius, if there is one. Clinics without a location are left
+out of radius searches.*/
+
+func filterClinicsByDistance(clinics []Clinic, searchConditionKeys searchConditions) []Clinic {
+	if searchConditionKeys.origin == nil {
+		return clinics
+	}
+
+	filteredData := make([]Clinic, 0, len(clinics))
+	for i := range clinics {
+		location, ok := clinics[i].coordinates()
+		if !ok {
+			if searchConditionKeys.radiusKm == 0 {
+				filteredData = append(filteredData, clinics[i])
+			}
+			continue
+		}
+
+		distance := haversineKm(*searchConditionKeys.origin, location)
+		if searchConditionKeys.radiusKm > 0 && distance > searchConditionKeys.radiusKm {
+			continue
+		}
+		filteredData = append(filteredData, clinics[i].withDistance(math.Round(distance*1000)/1000))
+	}
+	return filteredData
+}
@@ -0,0 +1,166 @@
+package clinics
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Media types of JSON and GeoJSON responses
+const (
+	jsonMediaType    = "application/json"
+	geoJSONMediaType = "application/geo+json"
+)
+
+/* geoJSONFeatureCollection is a page of clinics as a GeoJSON FeatureCollection. The paging fields
+facets and unavailable sources of ResponseData are added as foreign members.*/
+type geoJSONFeatureCollection struct {
+	Type          string           `json:"type"`
+	Features      []geoJSONFeature `json:"features"`
+	Total         int              `json:"total"`
+	NextCursor    string           `json:"next_cursor,omitempty"`
+	HasMore       bool             `json:"has_more"`
+	LastRefreshed *time.Time       `json:"last_refreshed,omitempty"`
+
+	Facets             map[string][]facetCount `json:"facets,omitempty"`
+	UnavailableSources []string                `json:"unavailable_sources,omitempty"`
+}
+
+// geoJSONFeature is a clinic as a Point feature, without geometry when the clinic has no location
+type geoJSONFeature struct {
+	Type       string                  `json:"type"`
+	Geometry   *geoJSONPoint           `json:"geometry"`
+	Properties clinicFeatureProperties `json:"properties"`
+}
+
+// geoJSONPoint is a GeoJSON Point, its coordinates are [longitude, latitude]
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// clinicFeatureProperties are the properties of a clinic feature
+type clinicFeatureProperties struct {
+	Name                string       `json:"name"`
+	State               string       `json:"state"`
+	StateCode           string       `json:"stateCode,omitempty"`
+	Type                string       `json:"type"`
+	Availability        openingHours `json:"availability,omitempty"`
+	WeeklyHours         weeklyHours  `json:"weeklyHours,omitempty"`
+	Closures            []string     `json:"closures,omitempty"`
+	Score               float64      `json:"score,omitempty"`
+	ApproximateLocation bool         `json:"approximateLocation,omitempty"`
+	DistanceKm          *float64     `json:"distanceKm,omitempty"`
+}
+
+/* [negotiateMediaType] - Pick the media type of the response from the offered ones by the
+Accept header of the request. Every offer gets the quality of the most specific media range
+matching it, an exact media type before a subtype wildcard before the full wildcard, and the best
+one wins, the first on ties. The first offer is the default, also when none of them is acceptable.*/
+
+func negotiateMediaType(r *http.Request, offers ...string) string {
+	best, bestQuality := offers[0], 0.0
+	for _, offer := range offers {
+		if quality := acceptQuality(r.Header.Values("Accept"), offer); quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+// acceptQuality is the quality of a media type in Accept headers, -1 when it is not listed
+func acceptQuality(accepts []string, mediaType string) float64 {
+	quality, specificity := -1.0, -1
+	mediaTypeParts := strings.SplitN(mediaType, "/", 2)
+
+	for _, accept := range accepts {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			params := strings.Split(mediaRange, ";")
+			rangeParts := strings.SplitN(strings.ToLower(strings.TrimSpace(params[0])), "/", 2)
+			if len(rangeParts) < 2 {
+				continue
+			}
+
+			rangeSpecificity := 0
+			switch {
+			case rangeParts[0] == mediaTypeParts[0] && rangeParts[1] == mediaTypeParts[1]:
+				rangeSpecificity = 2
+			case rangeParts[0] == mediaTypeParts[0] && rangeParts[1] == "*":
+				rangeSpecificity = 1
+			case rangeParts[0] != "*" || rangeParts[1] != "*":
+				continue
+			}
+			if rangeSpecificity <= specificity {
+				continue
+			}
+
+			specificity, quality = rangeSpecificity, 1
+			for _, param := range params[1:] {
+				nameValue := strings.SplitN(param, "=", 2)
+				if len(nameValue) == 2 && strings.ToLower(strings.TrimSpace(nameValue[0])) == "q" {
+					if q, err := strconv.ParseFloat(strings.TrimSpace(nameValue[1]), 64); err == nil {
+						quality = q
+					}
+				}
+			}
+		}
+	}
+	return quality
+}
+
+/* [newClinicFeatureCollection] - Convert a page of clinics into a GeoJSON FeatureCollection. Known
+clinic types are converted into the common clinic model first, so every type has the same
+properties.*/
+
+func newClinicFeatureCollection(clinics []Clinic, meta searchMeta) geoJSONFeatureCollection {
+	collection := geoJSONFeatureCollection{
+		Type:       "FeatureCollection",
+		Features:   make([]geoJSONFeature, 0, len(clinics)),
+		Total:      meta.total,
+		NextCursor: meta.nextCursor,
+		HasMore:    meta.hasMore,
+		Facets:     meta.facets,
+
+		UnavailableSources: meta.unavailableSources,
+	}
+	if !meta.lastRefreshed.IsZero() {
+		collection.LastRefreshed = &meta.lastRefreshed
+	}
+
+	for _, clinic := range clinics {
+		feature := geoJSONFeature{
+			Type: "Feature",
+			Properties: clinicFeatureProperties{
+				Name:      clinic.ClinicName(),
+				State:     clinic.ClinicState(),
+				StateCode: clinic.ClinicStateCode(),
+				Type:      clinic.ClinicType(),
+				Score:     clinic.ClinicScore(),
+			},
+		}
+		if clinicData, ok := toClinicInfo(clinic); ok {
+			feature.Properties.Availability = clinicData.Availablity
+			feature.Properties.WeeklyHours = clinicData.WeeklyHours
+			feature.Properties.Closures = clinicData.Closures
+			feature.Properties.ApproximateLocation = clinicData.ApproximateLocation
+		}
+		if location, ok := clinic.coordinates(); ok {
+			feature.Geometry = &geoJSONPoint{Type: "Point", Coordinates: [2]float64{location.lng, location.lat}}
+		}
+		if distance, ok := clinic.distance(); ok {
+			feature.Properties.DistanceKm = &distance
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+	return collection
+}
+
+/* [writeGeoJSONResponse] - Write a page of clinics as a GeoJSON FeatureCollection.*/
+
+func writeGeoJSONResponse(w http.ResponseWriter, clinics []Clinic, meta searchMeta, statusCode int) {
+	w.Header().Set("Content-Type", geoJSONMediaType)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(newClinicFeatureCollection(clinics, meta))
+}
@@ -0,0 +1,232 @@
+package clinics
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modes of matching the clinicName search phase against clinic names
+const (
+	nameMatchExact    = "exact"
+	nameMatchWord     = "word"
+	nameMatchPrefix   = "prefix"
+	nameMatchContains = "contains"
+	nameMatchFuzzy    = "fuzzy"
+)
+
+// defaultFuzzyDistance is the largest edit distance accepted by fuzzy matching by default
+const defaultFuzzyDistance = 2
+
+/* [validateNameMatchParams] - Validate the nameMatch and fuzzyDistance query params. Names are
+matched by whole words unless another mode is asked for.*/
+
+func validateNameMatchParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	searchConditionKeys.nameMatchMode = nameMatchWord
+	searchConditionKeys.fuzzyDistance = defaultFuzzyDistance
+
+	if keys, ok := queryParams["nameMatch"]; ok {
+		switch keys[0] {
+		case nameMatchExact, nameMatchWord, nameMatchPrefix, nameMatchContains, nameMatchFuzzy:
+			searchConditionKeys.nameMatchMode = keys[0]
+		default:
+			return errors.New("Please provide a valid value for nameMatch.")
+		}
+	}
+
+	if keys, ok := queryParams["fuzzyDistance"]; ok {
+		distance, err := strconv.Atoi(keys[0])
+		if err != nil || distance < 0 {
+			return errors.New("Please provide fuzzyDistance as a positive number.")
+		}
+		searchConditionKeys.fuzzyDistance = distance
+	}
+	return nil
+}
+
+/* [matchClinicName] - Common function to match a clinic name against the clinicName search phases
+using the requested mode. It returns whether the name matched any of them and the best relevance
+score between 0 and 1, where 1 is an exact match and longer partial matches score higher.
+	a) exact - the whole name
+	b) word - the whole name or a sequence of whole words of the name
+	c) prefix - the start of the name or of one of its words
+	d) contains - any part of the name
+	e) fuzzy - the whole name or a sequence of its words within fuzzyDistance edits*/
+
+func matchClinicName(clinicName string, searchConditionKeys searchConditions) (bool, float64) {
+	isMatched, bestScore := false, 0.0
+	// lower case once, it is a no-op for every search phase then
+	clinicName = strings.ToLower(clinicName)
+	for _, searchPhase := range searchConditionKeys.clinicNameSearchPhases {
+		if matched, score := matchClinicNamePhase(clinicName, searchPhase, searchConditionKeys); matched {
+			isMatched, bestScore = true, math.Max(bestScore, score)
+		}
+	}
+	return isMatched, bestScore
+}
+
+func matchClinicNamePhase(clinicName string, searchPhase string, searchConditionKeys searchConditions) (bool, float64) {
+	clinicNameLowerCase := strings.ToLower(clinicName)
+
+	if searchPhase == clinicNameLowerCase {
+		return true, 1
+	}
+	coverage := float64(len(searchPhase)) / float64(len(clinicNameLowerCase))
+
+	// the words of the name are walked in place, searches check thousands of names
+	switch searchConditionKeys.nameMatchMode {
+	case nameMatchWord:
+		if containsWordSequence(clinicNameLowerCase, searchPhase) {
+			return true, 0.8 + 0.2*coverage
+		}
+	case nameMatchPrefix:
+		if strings.HasPrefix(clinicNameLowerCase, searchPhase) {
+			return true, 0.7 + 0.3*coverage
+		}
+		for start, end := nextWord(clinicNameLowerCase, 0); start < len(clinicNameLowerCase); start, end = nextWord(clinicNameLowerCase, end) {
+			if joinedWordsHavePrefix(clinicNameLowerCase[start:], searchPhase) {
+				return true, 0.6 + 0.3*coverage
+			}
+		}
+	case nameMatchContains:
+		if strings.Contains(clinicNameLowerCase, searchPhase) {
+			return true, 0.5 + 0.5*coverage
+		}
+	case nameMatchFuzzy:
+		return matchFuzzyName(clinicNameLowerCase, strings.Fields(clinicNameLowerCase), searchPhase, len(strings.Fields(searchPhase)), searchConditionKeys.fuzzyDistance)
+	}
+	return false, 0
+}
+
+// nextWord finds the first word of s at or after i, split like strings.Fields; start is len(s) if there is none
+func nextWord(s string, i int) (int, int) {
+	start := i
+	for start < len(s) {
+		r, size := utf8.DecodeRuneInString(s[start:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	end := start
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		end += size
+	}
+	return start, end
+}
+
+// containsWordSequence checks if name holds all words of sequence next to each other
+func containsWordSequence(name string, sequence string) bool {
+	for start, end := nextWord(name, 0); start < len(name); start, end = nextWord(name, end) {
+		if wordsMatchAt(name, start, sequence) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordsMatchAt checks if the words of name from position i on start with all words of sequence
+func wordsMatchAt(name string, i int, sequence string) bool {
+	nameEnd, sequenceEnd := i, 0
+	for {
+		sequenceStart, nextSequenceEnd := nextWord(sequence, sequenceEnd)
+		if sequenceStart == len(sequence) {
+			// a sequence without words never matches
+			return sequenceEnd > 0
+		}
+		nameStart, nextNameEnd := nextWord(name, nameEnd)
+		if nameStart == len(name) || name[nameStart:nextNameEnd] != sequence[sequenceStart:nextSequenceEnd] {
+			return false
+		}
+		nameEnd, sequenceEnd = nextNameEnd, nextSequenceEnd
+	}
+}
+
+// joinedWordsHavePrefix checks if the words of s joined by single spaces start with prefix
+func joinedWordsHavePrefix(s string, prefix string) bool {
+	matched := 0
+	for start, end := nextWord(s, 0); start < len(s) && matched < len(prefix); start, end = nextWord(s, end) {
+		// words after the first one follow a single space
+		if matched > 0 {
+			if prefix[matched] != ' ' {
+				return false
+			}
+			matched++
+		}
+		word := s[start:end]
+		n := minInt(len(word), len(prefix)-matched)
+		if word[:n] != prefix[matched:matched+n] {
+			return false
+		}
+		matched += n
+	}
+	return matched == len(prefix)
+}
+
+/* [matchFuzzyName] - Compare the search phase with the whole name and with every run of as many
+name words as the phase has, and keep the closest one.*/
+
+func matchFuzzyName(clinicName string, clinicNameWords []string, searchPhase string, searchPhaseWordCount int, maxDistance int) (bool, float64) {
+	bestDistance := levenshteinDistance(clinicName, searchPhase)
+	bestScore := 1 - float64(bestDistance)/float64(maxInt(len([]rune(clinicName)), len([]rune(searchPhase))))
+
+	for i := 0; i+searchPhaseWordCount <= len(clinicNameWords); i++ {
+		candidate := strings.Join(clinicNameWords[i:i+searchPhaseWordCount], " ")
+		distance := levenshteinDistance(candidate, searchPhase)
+		// a run of words is never as good as the whole name
+		score := 0.9 * (1 - float64(distance)/float64(maxInt(len([]rune(candidate)), len([]rune(searchPhase)))))
+		if distance < bestDistance || (distance == bestDistance && score > bestScore) {
+			bestDistance, bestScore = distance, score
+		}
+	}
+
+	if bestDistance > maxDistance {
+		return false, 0
+	}
+	return true, bestScore
+}
+
+// levenshteinDistance counts the single character edits needed to turn a into b
+func levenshteinDistance(a, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+	previous := make([]int, len(bRunes)+1)
+	current := make([]int, len(bRunes)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(bRunes)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,71 @@
+package clinics
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMatchClinicNamePhase(t *testing.T) {
+	tests := []struct {
+		clinicName    string
+		searchPhase   string
+		nameMatchMode string
+		expected      bool
+	}{
+		{"Mayo Clinic", "mayo clinic", nameMatchExact, true},
+		{"Mayo Clinic", "mayo", nameMatchExact, false},
+		{"Mount Sinai Hospital", "sinai", nameMatchWord, true},
+		{"Mount Sinai Hospital", "sinai hospital", nameMatchWord, true},
+		{"Mount Sinai Hospital", "mount hospital", nameMatchWord, false},
+		{"Mount Sinai Hospital", "sin", nameMatchWord, false},
+		{"Mount  Sinai\tHospital", "sinai hospital", nameMatchWord, true},
+		{"Mount Sinai Hospital", "  ", nameMatchWord, false},
+		{"Mount Sinai Hospital", "sin", nameMatchPrefix, true},
+		{"Mount Sinai Hospital", "sinai hos", nameMatchPrefix, true},
+		{"Mount  Sinai Hospital", "mount sinai", nameMatchPrefix, true},
+		{"Mount Sinai Hospital", "ount", nameMatchPrefix, false},
+		{"Mount Sinai Hospital", "ount", nameMatchContains, true},
+		{"Mount Sinai Hospital", "mount sinia", nameMatchFuzzy, true},
+	}
+
+	for _, test := range tests {
+		searchConditionKeys := searchConditions{nameMatchMode: test.nameMatchMode, fuzzyDistance: defaultFuzzyDistance}
+		if matched, _ := matchClinicNamePhase(test.clinicName, test.searchPhase, searchConditionKeys); matched != test.expected {
+			t.Errorf("matchClinicNamePhase(%q, %q, %s) = %v, want %v", test.clinicName, test.searchPhase, test.nameMatchMode, matched, test.expected)
+		}
+	}
+}
+
+// TestNameWordsMatchFields checks the name words walked in place against splitting them with strings.Fields
+func TestNameWordsMatchFields(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "b", "ab", "ba", " ", "  ", "\t", "é"}
+	randomText := func() string {
+		var text strings.Builder
+		for i := random.Intn(6); i > 0; i-- {
+			text.WriteString(pieces[random.Intn(len(pieces))])
+		}
+		return text.String()
+	}
+
+	for i := 0; i < 20000; i++ {
+		name, phase := randomText(), randomText()
+		nameWords, phaseWords := strings.Fields(name), strings.Fields(phase)
+
+		expectedSequence := false
+		for j := 0; len(phaseWords) > 0 && j+len(phaseWords) <= len(nameWords); j++ {
+			if strings.Join(nameWords[j:j+len(phaseWords)], " ") == strings.Join(phaseWords, " ") {
+				expectedSequence = true
+			}
+		}
+		if containsWordSequence(name, phase) != expectedSequence {
+			t.Fatalf("containsWordSequence(%q, %q) = %v, want %v", name, phase, !expectedSequence, expectedSequence)
+		}
+
+		expectedPrefix := strings.HasPrefix(strings.Join(nameWords, " "), phase)
+		if joinedWordsHavePrefix(name, phase) != expectedPrefix {
+			t.Fatalf("joinedWordsHavePrefix(%q, %q) = %v, want %v", name, phase, !expectedPrefix, expectedPrefix)
+		}
+	}
+}
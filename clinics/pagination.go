@@ -0,0 +1,98 @@
+package clinics
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// maxPageLimit is the largest page size a client can ask for
+const maxPageLimit = 1000
+
+// pageCursor is the content of the opaque cursor returned as next_cursor
+type pageCursor struct {
+	Offset int `json:"o"`
+	Limit  int `json:"l,omitempty"`
+}
+
+/* [validatePageParams] - Validate limit, offset and cursor query params and store them in the
+search conditions. A cursor continues from where the previous page ended, so it can not be
+combined with offset.*/
+
+func validatePageParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	if keys, ok := queryParams["limit"]; ok {
+		limit, err := strconv.Atoi(keys[0])
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return errors.New("Please provide limit as a number between 1 and " + strconv.Itoa(maxPageLimit) + ".")
+		}
+		searchConditionKeys.limit = limit
+	}
+
+	if keys, ok := queryParams["offset"]; ok {
+		offset, err := strconv.Atoi(keys[0])
+		if err != nil || offset < 0 {
+			return errors.New("Please provide offset as a positive number.")
+		}
+		searchConditionKeys.offset = offset
+	}
+
+	if keys, ok := queryParams["cursor"]; ok {
+		if _, hasOffset := queryParams["offset"]; hasOffset {
+			return errors.New("Please provide either cursor or offset.")
+		}
+		cursor, err := decodeCursor(keys[0])
+		if err != nil {
+			return errors.New("Please provide a valid cursor.")
+		}
+		searchConditionKeys.offset = cursor.Offset
+		// the page size of the cursor is kept unless a new limit is provided
+		if searchConditionKeys.limit == 0 {
+			searchConditionKeys.limit = cursor.Limit
+		}
+	}
+	return nil
+}
+
+/* [paginate] - Work out the slice of a result list of the given length to return, and fill the
+paging metadata of the response. Without a limit every result from offset on is returned.*/
+
+func paginate(total int, searchConditionKeys searchConditions, meta *searchMeta) (int, int) {
+	start := searchConditionKeys.offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if searchConditionKeys.limit > 0 && start+searchConditionKeys.limit < total {
+		end = start + searchConditionKeys.limit
+	}
+
+	meta.total = total
+	meta.hasMore = end < total
+	if meta.hasMore {
+		meta.nextCursor = encodeCursor(pageCursor{Offset: end, Limit: searchConditionKeys.limit})
+	}
+	return start, end
+}
+
+func encodeCursor(cursor pageCursor) string {
+	dataByte, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(dataByte)
+}
+
+func decodeCursor(cursorStr string) (pageCursor, error) {
+	var cursor pageCursor
+	dataByte, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(dataByte, &cursor)
+	if err != nil {
+		return cursor, err
+	}
+	if cursor.Offset < 0 || cursor.Limit < 0 || cursor.Limit > maxPageLimit {
+		return cursor, errors.New("cursor out of range")
+	}
+	return cursor, nil
+}
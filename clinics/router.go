@@ -12,6 +12,12 @@ func SetClinicRoutes(router *mux.Router) *mux.Router {
 		middleware.SetMiddlewareJSON(SearchDentalClinicController)).Methods("GET")
 	router.HandleFunc("/clinics/get_vet_clinics",
 		middleware.SetMiddlewareJSON(SearchVetClinicController)).Methods("GET")
+	router.HandleFunc("/clinics/search",
+		middleware.SetMiddlewareJSON(SearchClinicController)).Methods("GET")
+	router.HandleFunc("/clinics/suggest",
+		middleware.SetMiddlewareJSON(SuggestClinicController)).Methods("GET")
+	router.HandleFunc("/clinics/status",
+		middleware.SetMiddlewareJSON(ClinicStatusController)).Methods("GET")
 
 	return router
 }
@@ -0,0 +1,215 @@
+package clinics
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format of closure dates and of the date query param
+const dateLayout = "2006-01-02"
+
+// now is the clock used by the openNow search condition
+var now = time.Now
+
+// weekdaysByKey finds a weekday by its short or full lower case name
+var weekdaysByKey = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+/* weeklyHours are the opening hours of a clinic per weekday, keyed by the short or full day name,
+e.g. {"mon": {"from": "08:00", "to": "17:00"}, "sun": []}. Days that are left out use the
+availability of the clinic, an empty list closes the clinic on that day.*/
+type weeklyHours map[string]openingHours
+
+/* clinicSchedule is the parsed form of the opening hours of a clinic, built once when the clinic
+lists are loaded. Hours are local to the time zone of the state of the clinic.*/
+type clinicSchedule struct {
+	daily       []timeInterval
+	weekly      map[time.Weekday][]timeInterval
+	closedDates map[string]bool
+	location    *time.Location
+}
+
+/* [newClinicSchedule] - Common function called while parsing the clinic lists to validate the
+availability, weekly hours and closure dates of a clinic. Invalid values are logged and left out.*/
+
+func newClinicSchedule(clinicType string, clinicName string, stateCode string, availability openingHours, weekly weeklyHours, closures []string) clinicSchedule {
+	schedule := clinicSchedule{
+		daily:    validateOpeningHours(clinicType, clinicName, availability),
+		location: stateLocation(stateCode),
+	}
+
+	if len(weekly) > 0 {
+		schedule.weekly = make(map[time.Weekday][]timeInterval, len(weekly))
+		for dayStr, hours := range weekly {
+			day, ok := weekdaysByKey[strings.ToLower(dayStr)]
+			if !ok {
+				log.Printf("Ignoring weekly hours of %s clinic %q: invalid day %q", clinicType, clinicName, dayStr)
+				continue
+			}
+			schedule.weekly[day] = validateOpeningHours(clinicType, clinicName, hours)
+		}
+	}
+
+	if len(closures) > 0 {
+		schedule.closedDates = make(map[string]bool, len(closures))
+		for _, dateStr := range closures {
+			if _, err := time.Parse(dateLayout, dateStr); err != nil {
+				log.Printf("Ignoring closure of %s clinic %q: invalid date %q", clinicType, clinicName, dateStr)
+				continue
+			}
+			schedule.closedDates[dateStr] = true
+		}
+	}
+	return schedule
+}
+
+/* [intervalsFor] - Opening intervals to check the openFrom and openTo search conditions against:
+the hours of the requested day, or the hours of every day of the week when no day is requested.
+The availability only counts for the days without weekly hours.*/
+
+func (schedule clinicSchedule) intervalsFor(searchConditionKeys searchConditions) []timeInterval {
+	if searchConditionKeys.dayStr != "" {
+		return schedule.intervalsOn(searchConditionKeys.day)
+	}
+	if len(schedule.weekly) == 0 {
+		return schedule.daily
+	}
+
+	var intervals []timeInterval
+	if len(schedule.weekly) < 7 {
+		intervals = append(intervals, schedule.daily...)
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		intervals = append(intervals, schedule.weekly[day]...)
+	}
+	return intervals
+}
+
+func (schedule clinicSchedule) intervalsOn(day time.Weekday) []timeInterval {
+	if dayIntervals, ok := schedule.weekly[day]; ok {
+		return dayIntervals
+	}
+	return schedule.daily
+}
+
+/* [isOpenOn] - Check if the clinic opens on the requested day and is not closed on the requested
+date. Overnight hours count for the day they start on.*/
+
+func (schedule clinicSchedule) isOpenOn(searchConditionKeys searchConditions) bool {
+	if searchConditionKeys.date != "" && schedule.closedDates[searchConditionKeys.date] {
+		return false
+	}
+	return len(schedule.intervalsOn(searchConditionKeys.day)) > 0
+}
+
+/* [isOpenAt] - Check if the clinic is open at the given instant, in the time zone of the clinic.
+Overnight hours of the day before are still open after midnight, unless that day was a closure.*/
+
+func (schedule clinicSchedule) isOpenAt(instant time.Time) bool {
+	location := schedule.location
+	if location == nil {
+		location = time.UTC
+	}
+	local := instant.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	if !schedule.closedDates[local.Format(dateLayout)] {
+		for _, interval := range schedule.intervalsOn(local.Weekday()) {
+			if interval.start <= minute && minute < interval.end {
+				return true
+			}
+		}
+	}
+
+	previousDay := local.AddDate(0, 0, -1)
+	if !schedule.closedDates[previousDay.Format(dateLayout)] {
+		for _, interval := range schedule.intervalsOn(previousDay.Weekday()) {
+			if minute+minutesPerDay < interval.end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* [matchOpening] - Common function to check the day, date, openNow and openAt search conditions,
+which always have to match.*/
+
+func (schedule clinicSchedule) matchOpening(searchConditionKeys searchConditions) bool {
+	if searchConditionKeys.dayStr != "" && !schedule.isOpenOn(searchConditionKeys) {
+		return false
+	}
+	if searchConditionKeys.openAtStr != "" && !schedule.isOpenAt(searchConditionKeys.openAt) {
+		return false
+	}
+	return true
+}
+
+/* [validateDayParams] - Validate the day and date query params. A date also sets the day, so both
+can only be provided together if they agree.*/
+
+func validateDayParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	if keys, ok := queryParams["day"]; ok {
+		day, ok := weekdaysByKey[strings.ToLower(keys[0])]
+		if !ok {
+			return errors.New("Please provide day as one of mon, tue, wed, thu, fri, sat, sun.")
+		}
+		searchConditionKeys.dayStr = keys[0]
+		searchConditionKeys.day = day
+	}
+
+	if keys, ok := queryParams["date"]; ok {
+		date, err := time.Parse(dateLayout, keys[0])
+		if err != nil {
+			return errors.New("Please provide date in year-month-day format.")
+		}
+		if searchConditionKeys.dayStr != "" && searchConditionKeys.day != date.Weekday() {
+			return errors.New("Please provide a day that matches the date.")
+		}
+		searchConditionKeys.dayStr = keys[0]
+		searchConditionKeys.day = date.Weekday()
+		searchConditionKeys.date = keys[0]
+	}
+	return nil
+}
+
+/* [validateOpenAtParams] - Validate the openNow and openAt query params. openNow=true searches the
+clinics open at the time of the request, openAt the clinics open at an RFC3339 time.*/
+
+func validateOpenAtParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	keys, hasOpenNow := queryParams["openNow"]
+	if hasOpenNow {
+		openNow, err := strconv.ParseBool(keys[0])
+		if err != nil {
+			return errors.New("Please provide openNow as true or false.")
+		}
+		if openNow {
+			searchConditionKeys.openAtStr = keys[0]
+			searchConditionKeys.openAt = now()
+		}
+	}
+
+	if keys, ok := queryParams["openAt"]; ok {
+		if hasOpenNow {
+			return errors.New("Please provide either openNow or openAt for search.")
+		}
+		openAt, err := time.Parse(time.RFC3339, keys[0])
+		if err != nil {
+			return errors.New("Please provide openAt as an RFC3339 time, e.g. 2026-01-02T15:04:05Z.")
+		}
+		searchConditionKeys.openAtStr = keys[0]
+		searchConditionKeys.openAt = openAt
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package clinics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIntervalsFor(t *testing.T) {
+	availability := openingHours{{From: "09:00", To: "17:00"}}
+	everyDay := weeklyHours{}
+	for _, day := range []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"} {
+		everyDay[day] = openingHours{{From: "10:00", To: "14:00"}}
+	}
+
+	tests := []struct {
+		name     string
+		weekly   weeklyHours
+		day      string
+		expected []timeInterval
+	}{
+		{"availability only", nil, "", []timeInterval{{540, 1020}}},
+		{"some weekly hours", weeklyHours{"sat": {{From: "10:00", To: "14:00"}}}, "", []timeInterval{{540, 1020}, {600, 840}}},
+		{"weekly hours on every day", everyDay, "", []timeInterval{
+			{600, 840}, {600, 840}, {600, 840}, {600, 840}, {600, 840}, {600, 840}, {600, 840},
+		}},
+		{"day with weekly hours", weeklyHours{"sat": {{From: "10:00", To: "14:00"}}}, "sat", []timeInterval{{600, 840}}},
+		{"day without weekly hours", weeklyHours{"sat": {{From: "10:00", To: "14:00"}}}, "mon", []timeInterval{{540, 1020}}},
+		{"closed day", weeklyHours{"sun": {}}, "sun", []timeInterval{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule := newClinicSchedule(dentalClinicType, "Test Clinic", "CA", availability, test.weekly, nil)
+			searchConditionKeys := searchConditions{dayStr: test.day, day: weekdaysByKey[test.day]}
+
+			intervals := schedule.intervalsFor(searchConditionKeys)
+			if len(intervals) == 0 && len(test.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(intervals, test.expected) {
+				t.Errorf("intervalsFor() = %v, want %v", intervals, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsOpenAt(t *testing.T) {
+	schedule := newClinicSchedule(vetClinicType, "Night Owl", "NV", openingHours{{From: "22:00", To: "06:00"}}, nil, []string{"2026-12-25"})
+	location := stateLocation("NV")
+
+	tests := []struct {
+		name     string
+		instant  time.Time
+		expected bool
+	}{
+		{"before opening", time.Date(2026, 10, 14, 21, 0, 0, 0, location), false},
+		{"after opening", time.Date(2026, 10, 14, 23, 0, 0, 0, location), true},
+		{"after midnight", time.Date(2026, 10, 15, 5, 59, 0, 0, location), true},
+		{"at closing", time.Date(2026, 10, 15, 6, 0, 0, 0, location), false},
+		{"on a closure", time.Date(2026, 12, 25, 23, 0, 0, 0, location), false},
+		{"after midnight of a closure", time.Date(2026, 12, 26, 2, 0, 0, 0, location), false},
+		{"in another time zone", time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if open := schedule.isOpenAt(test.instant); open != test.expected {
+				t.Errorf("isOpenAt(%v) = %v, want %v", test.instant, open, test.expected)
+			}
+		})
+	}
+}
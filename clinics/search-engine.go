@@ -0,0 +1,151 @@
+package clinics
+
+/*================================================================================================
+			[searchClinicList] - Common search engine for all clinic types
+	1) Keep all clinics if no search condition is provided
+	2) Look up the clinics that can match in the index
+	3) Keep the clinics which satisfy the search conditions, the filter expression and the radius
+	4) Count the requested facets of the clinics
+	5) Sort the clinics and return the requested page
+================================================================================================*/
+func searchClinicList(index *clinicIndex, searchConditionKeys searchConditions, searchOperator string, onlyTimeConditionExists bool, meta *searchMeta) []Clinic {
+	var filteredClinicData = []Clinic{}
+	clinics := index.candidates(searchConditionKeys, searchOperator)
+
+	// return all clinics if there is no search condition
+	if !searchConditionKeys.hasSearchKeys() {
+		// copy, the cached list must not be sorted in place
+		filteredClinicData = append(filteredClinicData, clinics...)
+	} else if searchOperator == "and" { //conditional functional call, based on search operator
+		filteredClinicData = searchClinicsBasedOnAndCondition(clinics, searchConditionKeys, onlyTimeConditionExists)
+	} else {
+		filteredClinicData = searchClinicsBasedOnOrCondition(clinics, searchConditionKeys)
+	}
+	filteredClinicData = filterClinicsByExpression(filteredClinicData, searchConditionKeys)
+	filteredClinicData = filterClinicsByDistance(filteredClinicData, searchConditionKeys)
+	if len(searchConditionKeys.facets) > 0 {
+		meta.facets = countFacets(filteredClinicData, searchConditionKeys)
+	}
+
+	start, end := paginate(len(filteredClinicData), searchConditionKeys, meta)
+	// only the clinics up to the end of the page have to be in order
+	sortClinics(filteredClinicData, searchConditionKeys.sortKeys, end)
+	return filteredClinicData[start:end]
+}
+
+/* [filterClinicsByExpression] - This function is used to filter out clinics that do not match the
+filter expression, if there is one.*/
+
+func filterClinicsByExpression(clinics []Clinic, searchConditionKeys searchConditions) []Clinic {
+	if searchConditionKeys.filter == nil {
+		return clinics
+	}
+
+	filteredData := make([]Clinic, 0)
+	for i := range clinics {
+		if searchConditionKeys.filter.eval(newFilterClinic(clinics[i], searchConditionKeys)) {
+			filteredData = append(filteredData, clinics[i])
+		}
+	}
+	return filteredData
+}
+
+/* [searchClinicsBasedOnAndCondition] - This function is used to filter out clinics based on
+search conditions and search operator = AND.*/
+
+func searchClinicsBasedOnAndCondition(clinics []Clinic, searchConditionKeys searchConditions, onlyTimeConditionExists bool) []Clinic {
+	filteredData := make([]Clinic, 0)
+
+	for i := range clinics {
+
+		// Search condition check for clininc name
+		isSearchConditionMatched := false
+
+		clinicData := clinics[i]
+		if len(searchConditionKeys.clinicNameSearchPhases) > 0 {
+			var score float64
+			isSearchConditionMatched, score = matchClinicName(clinicData.ClinicName(), searchConditionKeys)
+			// the full-text query ranks the clinics when there is one
+			if len(searchConditionKeys.textTerms) == 0 {
+				clinicData = clinicData.withScore(score)
+			}
+		}
+
+		// Search condition check for state
+		if len(searchConditionKeys.stateSearchPhases) > 0 {
+			if isSearchConditionMatched || len(searchConditionKeys.clinicNameSearchPhases) == 0 {
+				if matchClinicState(clinicData.ClinicStateCode(), clinicData.ClinicState(), searchConditionKeys) {
+					isSearchConditionMatched = true
+				} else {
+					isSearchConditionMatched = false
+				}
+			}
+		}
+
+		// Search condition check for opening and closing time
+		if onlyTimeConditionExists || isSearchConditionMatched {
+			if searchConditionKeys.hasTimeSearchKeys() {
+				isSearchConditionMatched = matchAvailability(clinicData.openingSchedule().intervalsFor(searchConditionKeys), searchConditionKeys)
+			}
+		}
+
+		// Search condition check for day, date and open now, which always has to match
+		if searchConditionKeys.hasOpeningSearchKeys() {
+			if isSearchConditionMatched || !searchConditionKeys.hasFieldSearchKeys() {
+				isSearchConditionMatched = clinicData.openingSchedule().matchOpening(searchConditionKeys)
+			}
+		}
+
+		if isSearchConditionMatched {
+			filteredData = append(filteredData, clinicData)
+		}
+	}
+	return filteredData
+}
+
+/* [searchClinicsBasedOnOrCondition] - This function is used to filter out clinics based on
+search conditions and search operator = OR.*/
+
+func searchClinicsBasedOnOrCondition(clinics []Clinic, searchConditionKeys searchConditions) []Clinic {
+	filteredData := make([]Clinic, 0)
+
+	for i := range clinics {
+
+		// Search condition check for clininc name
+		isSearchConditionMatched := false
+
+		clinicData := clinics[i]
+		if len(searchConditionKeys.clinicNameSearchPhases) > 0 {
+			var score float64
+			isSearchConditionMatched, score = matchClinicName(clinicData.ClinicName(), searchConditionKeys)
+			// the full-text query ranks the clinics when there is one
+			if len(searchConditionKeys.textTerms) == 0 {
+				clinicData = clinicData.withScore(score)
+			}
+		}
+
+		// Search condition check for state
+		if matchClinicState(clinicData.ClinicStateCode(), clinicData.ClinicState(), searchConditionKeys) {
+			isSearchConditionMatched = true
+		}
+
+		// Search condition check for opening and closing time
+		if searchConditionKeys.hasTimeSearchKeys() {
+			if matchAvailability(clinicData.openingSchedule().intervalsFor(searchConditionKeys), searchConditionKeys) {
+				isSearchConditionMatched = true
+			}
+		}
+
+		// Search condition check for day, date and open now, which always has to match
+		if searchConditionKeys.hasOpeningSearchKeys() {
+			if isSearchConditionMatched || !searchConditionKeys.hasFieldSearchKeys() {
+				isSearchConditionMatched = clinicData.openingSchedule().matchOpening(searchConditionKeys)
+			}
+		}
+
+		if isSearchConditionMatched {
+			filteredData = append(filteredData, clinicData)
+		}
+	}
+	return filteredData
+}
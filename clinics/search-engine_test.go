@@ -0,0 +1,133 @@
+package clinics
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// fixtureClinics are the dental and then the vet clinics of the memory source
+func fixtureClinics(t testing.TB) []Clinic {
+	dentalClinicData, err := parseDentalClinicList([]byte(dentalClinicsFixture))
+	if err != nil {
+		t.Fatalf("parsing the dental clinics fixture: %v", err)
+	}
+	vetClinicData, err := parseVetClinicList([]byte(vetClinicsFixture))
+	if err != nil {
+		t.Fatalf("parsing the vet clinics fixture: %v", err)
+	}
+	return mergeClinicLists(dentalClinics(dentalClinicData.([]dentalClinicInfo)), vetClinics(vetClinicData.([]vetClinicInfo)))
+}
+
+// searchConditionsOf validates the query params of a search like the services do
+func searchConditionsOf(t testing.TB, query string) (searchConditions, string, bool) {
+	queryParams, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("parsing query %q: %v", query, err)
+	}
+	searchConditionKeys, searchOperator, onlyTimeConditionExists, err := validateQueryParams(queryParams)
+	if err != nil {
+		t.Fatalf("validateQueryParams(%q) error = %v", query, err)
+	}
+	return searchConditionKeys, searchOperator, onlyTimeConditionExists
+}
+
+// clinicNames are the types and names of clinics, e.g. "vet: City Vet Clinic"
+func clinicNames(clinics []Clinic) []string {
+	names := make([]string, 0, len(clinics))
+	for _, clinic := range clinics {
+		names = append(names, clinic.ClinicType()+": "+clinic.ClinicName())
+	}
+	return names
+}
+
+func TestSearchClinicsBasedOnCondition(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// AND - every given condition has to match
+		{"clinicName=clinic&state=FL&condition=and", []string{"dental: Mayo Clinic"}},
+		{"clinicName=good&openFrom=10:00&condition=and", []string{"dental: Good Health Home", "vet: Good Health Home"}},
+		{"state=california&openFrom=12:00&openTo=23:00&condition=and", []string{"dental: Mount Sinai Hospital", "vet: National Veterinary Clinic"}},
+		{"openFrom=22:00&openTo=07:00&condition=and", []string{"vet: Night Owl Animal Hospital"}},
+		{"clinicName=mayo&state=NV&condition=and", []string{}},
+		{"state=NV,AK&day=sun&condition=and", []string{
+			"dental: Good Health Home", "dental: UAB Hospital", "vet: City Vet Clinic", "vet: Night Owl Animal Hospital",
+		}},
+
+		// OR - any given condition can match
+		{"clinicName=mayo&state=KS&condition=or", []string{"dental: Mayo Clinic", "dental: Tufts Medical Center", "vet: German Pets Clinics"}},
+		{"clinicName=hospital&openTo=06:00&condition=or", []string{
+			"dental: Hopkins Hospital Baltimore", "dental: Mount Sinai Hospital", "dental: UAB Hospital", "vet: Night Owl Animal Hospital",
+		}},
+		{"state=TN&openFrom=23:00", []string{"dental: Scratchpay Official practice"}},
+		{"clinicName=scratchpay", []string{
+			"dental: Scratchpay Test Pet Medical Center", "dental: Scratchpay Official practice", "vet: Scratchpay Test Pet Medical Center",
+		}},
+		{"clinicName=good,city&condition=or", []string{"dental: Good Health Home", "vet: Good Health Home", "vet: City Vet Clinic"}},
+		{"state=FL&day=sun&condition=or", []string{"dental: Mayo Clinic", "dental: Hopkins Hospital Baltimore", "vet: Good Health Home"}},
+	}
+
+	clinics := fixtureClinics(t)
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(t, test.query)
+
+			var result []Clinic
+			if searchOperator == "and" {
+				result = searchClinicsBasedOnAndCondition(clinics, searchConditionKeys, onlyTimeConditionExists)
+			} else {
+				result = searchClinicsBasedOnOrCondition(clinics, searchConditionKeys)
+			}
+			if names := clinicNames(result); !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("search %s = %q, want %q", searchOperator, names, test.expected)
+			}
+		})
+	}
+}
+
+func TestSearchClinicList(t *testing.T) {
+	tests := []struct {
+		query         string
+		expected      []string
+		expectedTotal int
+	}{
+		{"state=NV&sort=-name", []string{"vet: Night Owl Animal Hospital", "dental: Lunch Break Dental", "vet: City Vet Clinic"}, 3},
+		{"sort=openFrom,name&limit=2", []string{"dental: Scratchpay Official practice", "dental: Scratchpay Test Pet Medical Center"}, 17},
+		{"state=NV&filter=type=vet&limit=1&offset=1", []string{"vet: Night Owl Animal Hospital"}, 2},
+		{"q=clinic&clinicName=mayo&condition=and", []string{"dental: Mayo Clinic"}, 1},
+		{"lat=36.17&lng=-115.14&radiusKm=10", []string{"dental: Lunch Break Dental", "vet: City Vet Clinic"}, 2},
+		// clinics located at the center of their state are left out of areas
+		{"bbox=-125,30,-110,40&state=CA&condition=and", []string{"dental: Mount Sinai Hospital", "vet: National Veterinary Clinic"}, 2},
+		{"bbox=-121,35,-119,37", []string{}, 0},
+	}
+
+	index := newClinicIndex(fixtureClinics(t))
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(t, test.query)
+
+			var meta searchMeta
+			result := searchClinicList(index, searchConditionKeys, searchOperator, onlyTimeConditionExists, &meta)
+			if names := clinicNames(result); !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("searchClinicList() = %q, want %q", names, test.expected)
+			}
+			if meta.total != test.expectedTotal {
+				t.Errorf("total = %d, want %d", meta.total, test.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestSearchClinicListKeepsTextScore(t *testing.T) {
+	index := newClinicIndex(fixtureClinics(t))
+	searchConditionKeys, searchOperator, onlyTimeConditionExists := searchConditionsOf(t, "q=clinic&clinicName=mayo&state=KS&condition=or")
+
+	var meta searchMeta
+	for _, clinic := range searchClinicList(index, searchConditionKeys, searchOperator, onlyTimeConditionExists, &meta) {
+		if clinic.ClinicScore() == 0 {
+			t.Errorf("score of %s = 0, want the score of q", clinic.ClinicName())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package clinics
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Clinic types used in the unified clinic model
+const (
+	dentalClinicType = "dental"
+	vetClinicType    = "vet"
+)
+
+type clinicInfo struct {
+	Name                string       `json:"name"`
+	State               string       `json:"state"`
+	StateName           string       `json:"stateName,omitempty"`
+	StateCode           string       `json:"stateCode,omitempty"`
+	Availablity         openingHours `json:"availability"`
+	WeeklyHours         weeklyHours  `json:"weeklyHours,omitempty"`
+	Closures            []string     `json:"closures,omitempty"`
+	Type                string       `json:"type"`
+	Score               float64      `json:"score,omitempty"`
+	Lat                 *float64     `json:"lat,omitempty"`
+	Lng                 *float64     `json:"lng,omitempty"`
+	ApproximateLocation bool         `json:"approximateLocation,omitempty"`
+	DistanceKm          *float64     `json:"distanceKm,omitempty"`
+
+	schedule clinicSchedule
+}
+
+/*================================================================================================
+			[SearchClinics] - Search Dental and Vet Clinics together
+	1) Fetch dental and vet clinics concurrently
+	2) Merge both lists into the common clinic model, once per refresh of either list
+	3) Search the merged list like any other clinic list
+================================================================================================*/
+func SearchClinics(r *http.Request) ([]Clinic, searchMeta, int, error) {
+	queryParams := r.URL.Query()
+	searchConditionKeys, searchOperator, onlyTimeConditionExists, err := validateQueryParams(queryParams)
+	if err != nil {
+		return nil, searchMeta{}, 400, err
+	}
+
+	mergedIndex, lastRefreshed, unavailableSources, err := getCachedMergedClinicIndex(r.Context())
+	if err != nil {
+		return nil, searchMeta{}, 500, err
+	}
+	meta := searchMeta{lastRefreshed: lastRefreshed, unavailableSources: unavailableSources}
+
+	result := searchClinicList(mergedIndex, searchConditionKeys, searchOperator, onlyTimeConditionExists, &meta)
+	return result, meta, 200, nil
+}
+
+/* [getCachedMergedClinicIndex] - Fetch the dental and vet clinics from the cache concurrently and
+get the index of both lists merged, along with the time the older of the two lists was loaded.
+When only one of the lists can be loaded the other one is left out, and its clinic type is
+returned as an unavailable source; it fails only when neither list can be loaded.*/
+
+func getCachedMergedClinicIndex(ctx context.Context) (*clinicIndex, time.Time, []string, error) {
+	var dentalClinicIndex, vetClinicIndex *clinicIndex
+	var dentalRefreshed, vetRefreshed time.Time
+	var dentalErr, vetErr error
+
+	// fetch both lists concurrently
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dentalClinicIndex, dentalRefreshed, dentalErr = getCachedDentalClinicList(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		vetClinicIndex, vetRefreshed, vetErr = getCachedVetClinicList(ctx)
+	}()
+	wg.Wait()
+
+	var unavailableSources []string
+	switch {
+	case dentalErr != nil && vetErr != nil:
+		return nil, time.Time{}, nil, dentalErr
+	case dentalErr != nil:
+		log.Println("Searching clinics without the dental clinics:", dentalErr)
+		dentalClinicIndex, dentalRefreshed = emptyClinicIndex, vetRefreshed
+		unavailableSources = []string{dentalClinicType}
+	case vetErr != nil:
+		log.Println("Searching clinics without the vet clinics:", vetErr)
+		vetClinicIndex, vetRefreshed = emptyClinicIndex, dentalRefreshed
+		unavailableSources = []string{vetClinicType}
+	}
+
+	// the merged list is only as fresh as the older of the two lists
+	lastRefreshed := dentalRefreshed
+	if vetRefreshed.Before(dentalRefreshed) {
+		lastRefreshed = vetRefreshed
+	}
+	return getMergedClinicIndex(dentalClinicIndex, vetClinicIndex), lastRefreshed, unavailableSources, nil
+}
+
+// emptyClinicIndex stands in for a clinic list that can not be loaded, the same one every time so
+// the merged index is only rebuilt when the list comes back
+var emptyClinicIndex = newClinicIndex([]Clinic{})
+
+// mergedClinicIndex is the index of the merged clinic list and the indexes it was merged from
+var mergedClinicIndex struct {
+	sync.Mutex
+	dental, vet, merged *clinicIndex
+}
+
+/* [getMergedClinicIndex] - Get the index of the merged dental and vet clinic list, merging the
+lists again only when either of them was refreshed.*/
+
+func getMergedClinicIndex(dentalClinicIndex *clinicIndex, vetClinicIndex *clinicIndex) *clinicIndex {
+	mergedClinicIndex.Lock()
+	defer mergedClinicIndex.Unlock()
+
+	if mergedClinicIndex.dental != dentalClinicIndex || mergedClinicIndex.vet != vetClinicIndex {
+		mergedClinicIndex.merged = newClinicIndex(mergeClinicLists(dentalClinicIndex.clinics, vetClinicIndex.clinics))
+		mergedClinicIndex.dental, mergedClinicIndex.vet = dentalClinicIndex, vetClinicIndex
+	}
+	return mergedClinicIndex.merged
+}
+
+/* [mergeClinicLists] - Normalize dental and vet clinics into the common clinic model.*/
+
+func mergeClinicLists(dentalClinicData []Clinic, vetClinicData []Clinic) []Clinic {
+	mergedData := make([]Clinic, 0, len(dentalClinicData)+len(vetClinicData))
+
+	for _, clinic := range append(append([]Clinic{}, dentalClinicData...), vetClinicData...) {
+		if clinicData, ok := toClinicInfo(clinic); ok {
+			mergedData = append(mergedData, clinicData)
+		} else {
+			mergedData = append(mergedData, clinic)
+		}
+	}
+	return mergedData
+}
+
+/* [toClinicInfo] - Convert a dental or vet clinic into the common clinic model. Other clinics
+are not converted. The state is the name of the state for every clinic type when it is known.*/
+
+func toClinicInfo(clinic Clinic) (clinicInfo, bool) {
+	switch clinicData := clinic.(type) {
+	case clinicInfo:
+		return clinicData, true
+	case dentalClinicInfo:
+		return clinicInfo{
+			Name:        clinicData.Name,
+			State:       clinicData.State,
+			StateName:   clinicData.stateName(),
+			StateCode:   clinicData.StateCode,
+			Availablity: clinicData.Availablity,
+			WeeklyHours: clinicData.WeeklyHours,
+			Closures:    clinicData.Closures,
+			Type:        dentalClinicType,
+			Score:       clinicData.Score,
+
+			Lat:                 clinicData.Lat,
+			Lng:                 clinicData.Lng,
+			ApproximateLocation: clinicData.ApproximateLocation,
+			DistanceKm:          clinicData.DistanceKm,
+
+			schedule: clinicData.schedule,
+		}, true
+	case vetClinicInfo:
+		return clinicInfo{
+			Name:        clinicData.Name,
+			State:       clinicData.ClinicState(),
+			StateName:   clinicData.StateName,
+			StateCode:   clinicData.stateCode(),
+			Availablity: clinicData.Availablity,
+			WeeklyHours: clinicData.WeeklyHours,
+			Closures:    clinicData.Closures,
+			Type:        vetClinicType,
+			Score:       clinicData.Score,
+
+			Lat:                 clinicData.Lat,
+			Lng:                 clinicData.Lng,
+			ApproximateLocation: clinicData.ApproximateLocation,
+			DistanceKm:          clinicData.DistanceKm,
+
+			schedule: clinicData.schedule,
+		}, true
+	}
+	return clinicInfo{}, false
+}
@@ -0,0 +1,57 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeClinicListsState(t *testing.T) {
+	for _, clinic := range fixtureClinics(t) {
+		clinicData := clinic.(clinicInfo)
+		state, ok := normalizeState(clinicData.StateCode)
+		if !ok {
+			t.Fatalf("state code of %s clinic %q = %q, want a known state", clinicData.Type, clinicData.Name, clinicData.StateCode)
+		}
+		if clinicData.State != state.name || clinicData.StateName != state.name {
+			t.Errorf("state of %s clinic %q = %q, %q, want %q", clinicData.Type, clinicData.Name, clinicData.State, clinicData.StateName, state.name)
+		}
+	}
+}
+
+func TestGetCachedMergedClinicIndexWithUnavailableSource(t *testing.T) {
+	dentalCache, vetCache := dentalClinicCache, vetClinicCache
+	defer func() { dentalClinicCache, vetClinicCache = dentalCache, vetCache }()
+
+	loadErr := errors.New("upstream down")
+	failing := func(ctx context.Context, previous interface{}) (interface{}, error) { return nil, loadErr }
+	vetClinicData, err := parseVetClinicList([]byte(vetClinicsFixture))
+	if err != nil {
+		t.Fatalf("parsing the vet clinics fixture: %v", err)
+	}
+	vetIndex := newClinicIndex(mergeClinicLists(nil, vetClinics(vetClinicData.([]vetClinicInfo))))
+	dentalClinicCache = newClinicCache(dentalClinicType, failing)
+	vetClinicCache = newClinicCache(vetClinicType, func(ctx context.Context, previous interface{}) (interface{}, error) {
+		return vetIndex, nil
+	})
+
+	index, lastRefreshed, unavailableSources, err := getCachedMergedClinicIndex(context.Background())
+	if err != nil {
+		t.Fatalf("getCachedMergedClinicIndex() error = %v, want the vet clinics", err)
+	}
+	if !reflect.DeepEqual(clinicNames(index.clinics), clinicNames(vetIndex.clinics)) {
+		t.Errorf("clinics = %q, want %q", clinicNames(index.clinics), clinicNames(vetIndex.clinics))
+	}
+	if lastRefreshed.IsZero() {
+		t.Errorf("last refreshed is zero, want the time the vet clinics were loaded")
+	}
+	if !reflect.DeepEqual(unavailableSources, []string{dentalClinicType}) {
+		t.Errorf("unavailable sources = %q, want %q", unavailableSources, dentalClinicType)
+	}
+
+	vetClinicCache = newClinicCache(vetClinicType, failing)
+	if _, _, _, err := getCachedMergedClinicIndex(context.Background()); !errors.Is(err, loadErr) {
+		t.Errorf("getCachedMergedClinicIndex() error = %v, want %v when no clinics can be loaded", err, loadErr)
+	}
+}
@@ -0,0 +1,286 @@
+package clinics
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fields clinics can be sorted by, in the order used to break ties
+var sortFields = []string{"name", "state", "openFrom", "openTo", "type", "score", "distance"}
+
+type sortKey struct {
+	field      string
+	descending bool
+}
+
+/* [validateSortParams] - Validate the sort query param, a comma separated list of fields where a
+leading "-" sorts descending, e.g. sort=state,-openFrom,name. When a nameMatch mode or a full-text
+query is asked for without a sort, the best matching clinics come first, otherwise the nearest
+clinics come first when a location is searched around. Sorting by distance needs that location.*/
+
+func validateSortParams(queryParams url.Values, searchConditionKeys *searchConditions) error {
+	keys, ok := queryParams["sort"]
+	if !ok {
+		_, rankedByName := queryParams["nameMatch"]
+		if _, rankedByText := queryParams["q"]; rankedByName || rankedByText {
+			searchConditionKeys.sortKeys = []sortKey{{field: "score", descending: true}}
+		} else if searchConditionKeys.origin != nil {
+			searchConditionKeys.sortKeys = []sortKey{{field: "distance"}}
+		}
+		return nil
+	}
+
+	for _, keyStr := range strings.Split(keys[0], ",") {
+		key := sortKey{field: strings.TrimSpace(keyStr)}
+		if strings.HasPrefix(key.field, "-") {
+			key.descending = true
+			key.field = key.field[1:]
+		} else {
+			key.field = strings.TrimPrefix(key.field, "+")
+		}
+
+		if !isSortField(key.field) {
+			return errors.New("Please provide a valid sort key, one of " + strings.Join(sortFields, ", ") + ".")
+		}
+		if key.field == "distance" && searchConditionKeys.origin == nil {
+			return errors.New("Please provide lat and lng to sort by distance.")
+		}
+		searchConditionKeys.sortKeys = append(searchConditionKeys.sortKeys, key)
+	}
+	return nil
+}
+
+func isSortField(field string) bool {
+	for i := range sortFields {
+		if sortFields[i] == field {
+			return true
+		}
+	}
+	return false
+}
+
+/* clinicSortValues are the values a clinic is sorted by, worked out once per clinic before
+sorting. Names and states are compared ignoring case, opening hours are the earliest opening and
+latest closing time of any day in minutes, and clinics without opening hours or distance sort last.*/
+type clinicSortValues struct {
+	name       string
+	state      string
+	clinicType string
+	openFrom   int
+	openTo     int
+	score      float64
+	distance   float64
+}
+
+func newClinicSortValues(clinic Clinic) clinicSortValues {
+	values := clinicSortValues{
+		name:       clinic.ClinicName(),
+		state:      clinic.ClinicState(),
+		clinicType: clinic.ClinicType(),
+		openFrom:   math.MaxInt32,
+		openTo:     math.MaxInt32,
+		score:      clinic.ClinicScore(),
+		distance:   math.Inf(1),
+	}
+
+	intervals := clinic.openingSchedule().intervalsFor(searchConditions{})
+	if len(intervals) > 0 {
+		values.openFrom, values.openTo = intervals[0].start, intervals[0].end
+		for _, interval := range intervals[1:] {
+			values.openFrom = minInt(values.openFrom, interval.start)
+			values.openTo = maxInt(values.openTo, interval.end)
+		}
+	}
+	if distance, ok := clinic.distance(); ok {
+		values.distance = distance
+	}
+	return values
+}
+
+/* [compare] - Common function to compare the values of two clinics by a sort field, negative when
+a sorts first.*/
+
+func (a clinicSortValues) compare(b clinicSortValues, field string) int {
+	switch field {
+	case "name":
+		return compareLowerCase(a.name, b.name)
+	case "state":
+		return compareLowerCase(a.state, b.state)
+	case "openFrom":
+		return compareInts(a.openFrom, b.openFrom)
+	case "openTo":
+		return compareInts(a.openTo, b.openTo)
+	case "type":
+		return strings.Compare(a.clinicType, b.clinicType)
+	case "score":
+		return compareFloats(a.score, b.score)
+	case "distance":
+		return compareFloats(a.distance, b.distance)
+	}
+	return 0
+}
+
+// compareLowerCase compares two strings like their lower case, without converting ASCII strings
+func compareLowerCase(a string, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		charA, charB := a[i], b[i]
+		if charA >= utf8.RuneSelf || charB >= utf8.RuneSelf {
+			return strings.Compare(strings.ToLower(a[i:]), strings.ToLower(b[i:]))
+		}
+		if 'A' <= charA && charA <= 'Z' {
+			charA += 'a' - 'A'
+		}
+		if 'A' <= charB && charB <= 'Z' {
+			charB += 'a' - 'A'
+		}
+		if charA != charB {
+			return compareInts(int(charA), int(charB))
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// clinicSorter sorts clinics together with their sort values
+type clinicSorter struct {
+	clinics []Clinic
+	values  []clinicSortValues
+	keys    []sortKey
+}
+
+func (sorter clinicSorter) Len() int { return len(sorter.clinics) }
+
+func (sorter clinicSorter) Swap(i, j int) {
+	sorter.clinics[i], sorter.clinics[j] = sorter.clinics[j], sorter.clinics[i]
+	sorter.values[i], sorter.values[j] = sorter.values[j], sorter.values[i]
+}
+
+func (sorter clinicSorter) Less(i, j int) bool {
+	return compareClinics(sorter.values[i], sorter.values[j], sorter.keys) < 0
+}
+
+/* [sortClinics] - Sort a list of clinics in place by the given keys, so that the first count
+clinics are in order. Ties are broken by the remaining sortFields and then by the original order,
+so the result is deterministic. When count is less than the whole list, e.g. the first pages, the
+first count clinics are picked with a heap and the rest are left after them in their original
+order.*/
+
+func sortClinics(clinics []Clinic, keys []sortKey, count int) {
+	if len(keys) == 0 || count <= 0 {
+		return
+	}
+
+	values := make([]clinicSortValues, len(clinics))
+	for i := range clinics {
+		values[i] = newClinicSortValues(clinics[i])
+	}
+	if count >= len(clinics) {
+		sort.Stable(clinicSorter{clinics: clinics, values: values, keys: keys})
+		return
+	}
+
+	// keep the count positions that sort first, with the one that sorts last on top
+	first := &clinicHeap{values: values, keys: keys}
+	for position := range clinics {
+		if first.Len() < count {
+			heap.Push(first, position)
+		} else if first.before(position, first.positions[0]) {
+			first.positions[0] = position
+			heap.Fix(first, 0)
+		}
+	}
+	sort.Slice(first.positions, func(i, j int) bool {
+		return first.before(first.positions[i], first.positions[j])
+	})
+
+	picked := make([]bool, len(clinics))
+	sorted := make([]Clinic, 0, len(clinics))
+	for _, position := range first.positions {
+		picked[position] = true
+		sorted = append(sorted, clinics[position])
+	}
+	for position := range clinics {
+		if !picked[position] {
+			sorted = append(sorted, clinics[position])
+		}
+	}
+	copy(clinics, sorted)
+}
+
+// clinicHeap is a heap of clinic positions with the position that sorts last on top
+type clinicHeap struct {
+	positions []int
+	values    []clinicSortValues
+	keys      []sortKey
+}
+
+// before checks if the clinic at position a sorts before the one at b, the earlier one on ties
+func (h *clinicHeap) before(a, b int) bool {
+	if result := compareClinics(h.values[a], h.values[b], h.keys); result != 0 {
+		return result < 0
+	}
+	return a < b
+}
+
+func (h *clinicHeap) Len() int           { return len(h.positions) }
+func (h *clinicHeap) Less(i, j int) bool { return h.before(h.positions[j], h.positions[i]) }
+func (h *clinicHeap) Swap(i, j int)      { h.positions[i], h.positions[j] = h.positions[j], h.positions[i] }
+func (h *clinicHeap) Push(x interface{}) { h.positions = append(h.positions, x.(int)) }
+
+func (h *clinicHeap) Pop() interface{} {
+	position := h.positions[len(h.positions)-1]
+	h.positions = h.positions[:len(h.positions)-1]
+	return position
+}
+
+func compareClinics(a, b clinicSortValues, keys []sortKey) int {
+	for _, key := range keys {
+		if result := a.compare(b, key.field); result != 0 {
+			if key.descending {
+				return -result
+			}
+			return result
+		}
+	}
+
+	// tie-breaking
+	for _, field := range sortFields {
+		if result := a.compare(b, field); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package clinics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareLowerCase(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"Mayo Clinic", "mayo clinic", 0},
+		{"Mayo", "mayo clinic", -1},
+		{"mayo clinic", "Mayo", 1},
+		{"Zahn", "apple", 1},
+		{"a_b", "A[b", 1},
+		{"Émile", "émile", 0},
+		{"Ärzte", "Zahn", 1},
+		{"Zoë", "ZOË", 0},
+	}
+
+	for _, test := range tests {
+		if result := compareLowerCase(test.a, test.b); result != test.expected {
+			t.Errorf("compareLowerCase(%q, %q) = %d, want %d", test.a, test.b, result, test.expected)
+		}
+	}
+}
+
+func TestSortClinicsPage(t *testing.T) {
+	clinics := generateClinics(t, 2000)
+	keys := []sortKey{{field: "openFrom"}, {field: "state", descending: true}}
+
+	sorted := append([]Clinic{}, clinics...)
+	sortClinics(sorted, keys, len(sorted))
+	for _, count := range []int{1, 10, 150} {
+		page := append([]Clinic{}, clinics...)
+		sortClinics(page, keys, count)
+		if !reflect.DeepEqual(page[:count], sorted[:count]) {
+			t.Errorf("first %d clinics = %q, want %q", count, clinicNames(page[:count]), clinicNames(sorted[:count]))
+		}
+		if len(page) != len(clinics) {
+			t.Errorf("sorting the first %d clinics left %d clinics, want %d", count, len(page), len(clinics))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package clinics
+
+import "math"
+
+// Size of the cells of the spatial grid in degrees
+const gridCellDegrees = 1.0
+
+type gridCell struct {
+	row int
+	col int
+}
+
+/* spatialGrid divides the map into cells of gridCellDegrees and keeps the clinics located in each
+of them, so area searches only check the clinics in the cells the area covers. Clinics placed at
+the center of their state are left out, a map view over that center would find all of them and
+views over where they really are none.*/
+type spatialGrid struct {
+	// cell to the positions of the clinics in it, ascending
+	cells map[gridCell][]int
+}
+
+func newSpatialGrid(clinics []Clinic) *spatialGrid {
+	grid := &spatialGrid{cells: make(map[gridCell][]int)}
+	for i, clinic := range clinics {
+		if location, ok := clinic.coordinates(); ok && !clinic.approximateLocation() {
+			cell := cellOf(location.lat, location.lng)
+			grid.cells[cell] = appendPosition(grid.cells[cell], i)
+		}
+	}
+	return grid
+}
+
+// cellOf is the cell of a location, the poles and the antimeridian belong to the last cells
+func cellOf(lat float64, lng float64) gridCell {
+	rows, cols := int(180/gridCellDegrees), int(360/gridCellDegrees)
+	return gridCell{
+		row: minInt(int(math.Floor((lat+90)/gridCellDegrees)), rows-1),
+		col: minInt(int(math.Floor((lng+180)/gridCellDegrees)), cols-1),
+	}
+}
+
+/* [search] - Positions of the clinics in the cells the box covers, ascending. Clinics near the
+edges of the box can be outside of it. When the box covers more cells than have clinics, the
+cells with clinics are checked instead.*/
+
+func (grid *spatialGrid) search(box geoBox) []int {
+	first, last := cellOf(box.minLat, box.minLng), cellOf(box.maxLat, box.maxLng)
+
+	var cellPositions [][]int
+	if (last.row-first.row+1)*(last.col-first.col+1) > len(grid.cells) {
+		for cell, positions := range grid.cells {
+			if cell.row >= first.row && cell.row <= last.row && cell.col >= first.col && cell.col <= last.col {
+				cellPositions = append(cellPositions, positions)
+			}
+		}
+	} else {
+		for row := first.row; row <= last.row; row++ {
+			for col := first.col; col <= last.col; col++ {
+				if positions, ok := grid.cells[gridCell{row: row, col: col}]; ok {
+					cellPositions = append(cellPositions, positions)
+				}
+			}
+		}
+	}
+	return unionPositions(cellPositions...)
+}
+
+/* [areaCandidates] - Positions of the clinics inside every searched area, looked up in the
+spatial grid and checked against the area. It is not narrowed when no area is searched.*/
+
+func (index *clinicIndex) areaCandidates(searchConditionKeys searchConditions) ([]int, bool) {
+	if len(searchConditionKeys.areas) == 0 {
+		return nil, false
+	}
+
+	var areaPositions []int
+	for i, area := range searchConditionKeys.areas {
+		var boxPositions [][]int
+		for _, box := range area.bounds() {
+			boxPositions = append(boxPositions, index.grid.search(box))
+		}
+
+		positions := make([]int, 0)
+		for _, position := range unionPositions(boxPositions...) {
+			if location, ok := index.clinics[position].coordinates(); ok && area.contains(location) {
+				positions = append(positions, position)
+			}
+		}
+
+		if i == 0 {
+			areaPositions = positions
+		} else {
+			areaPositions = intersectPositions(areaPositions, positions)
+		}
+	}
+	return areaPositions, true
+}
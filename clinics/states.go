@@ -0,0 +1,143 @@
+package clinics
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* usState is a US state, district or territory with its two-letter postal code, the time zone
+most of it is in, used to evaluate the opening hours of its clinics, and its approximate center,
+used to locate clinics without coordinates.*/
+type usState struct {
+	name      string
+	code      string
+	timeZone  string
+	latitude  float64
+	longitude float64
+}
+
+var usStates = []usState{
+	{"Alabama", "AL", "America/Chicago", 32.8067, -86.7911},
+	{"Alaska", "AK", "America/Anchorage", 61.3707, -152.4044},
+	{"Arizona", "AZ", "America/Phoenix", 33.7298, -111.4312},
+	{"Arkansas", "AR", "America/Chicago", 34.9697, -92.3731},
+	{"California", "CA", "America/Los_Angeles", 36.1162, -119.6816},
+	{"Colorado", "CO", "America/Denver", 39.0598, -105.3111},
+	{"Connecticut", "CT", "America/New_York", 41.5978, -72.7554},
+	{"Delaware", "DE", "America/New_York", 39.3185, -75.5071},
+	{"District of Columbia", "DC", "America/New_York", 38.8974, -77.0268},
+	{"Florida", "FL", "America/New_York", 27.7663, -81.6868},
+	{"Georgia", "GA", "America/New_York", 33.0406, -83.6431},
+	{"Hawaii", "HI", "Pacific/Honolulu", 21.0943, -157.4983},
+	{"Idaho", "ID", "America/Boise", 44.2405, -114.4788},
+	{"Illinois", "IL", "America/Chicago", 40.3495, -88.9861},
+	{"Indiana", "IN", "America/Indiana/Indianapolis", 39.8494, -86.2583},
+	{"Iowa", "IA", "America/Chicago", 42.0115, -93.2105},
+	{"Kansas", "KS", "America/Chicago", 38.5266, -96.7265},
+	{"Kentucky", "KY", "America/New_York", 37.6681, -84.6701},
+	{"Louisiana", "LA", "America/Chicago", 31.1695, -91.8678},
+	{"Maine", "ME", "America/New_York", 44.6939, -69.3819},
+	{"Maryland", "MD", "America/New_York", 39.0639, -76.8021},
+	{"Massachusetts", "MA", "America/New_York", 42.2302, -71.5301},
+	{"Michigan", "MI", "America/Detroit", 43.3266, -84.5361},
+	{"Minnesota", "MN", "America/Chicago", 45.6945, -93.9002},
+	{"Mississippi", "MS", "America/Chicago", 32.7416, -89.6787},
+	{"Missouri", "MO", "America/Chicago", 38.4561, -92.2884},
+	{"Montana", "MT", "America/Denver", 46.9219, -110.4544},
+	{"Nebraska", "NE", "America/Chicago", 41.1254, -98.2681},
+	{"Nevada", "NV", "America/Los_Angeles", 38.3135, -117.0554},
+	{"New Hampshire", "NH", "America/New_York", 43.4525, -71.5639},
+	{"New Jersey", "NJ", "America/New_York", 40.2989, -74.5210},
+	{"New Mexico", "NM", "America/Denver", 34.8405, -106.2485},
+	{"New York", "NY", "America/New_York", 42.1657, -74.9481},
+	{"North Carolina", "NC", "America/New_York", 35.6301, -79.8064},
+	{"North Dakota", "ND", "America/Chicago", 47.5289, -99.7840},
+	{"Ohio", "OH", "America/New_York", 40.3888, -82.7649},
+	{"Oklahoma", "OK", "America/Chicago", 35.5653, -96.9289},
+	{"Oregon", "OR", "America/Los_Angeles", 44.5720, -122.0709},
+	{"Pennsylvania", "PA", "America/New_York", 40.5908, -77.2098},
+	{"Rhode Island", "RI", "America/New_York", 41.6809, -71.5118},
+	{"South Carolina", "SC", "America/New_York", 33.8569, -80.9450},
+	{"South Dakota", "SD", "America/Chicago", 44.2998, -99.4388},
+	{"Tennessee", "TN", "America/Chicago", 35.7478, -86.6923},
+	{"Texas", "TX", "America/Chicago", 31.0545, -97.5635},
+	{"Utah", "UT", "America/Denver", 40.1500, -111.8624},
+	{"Vermont", "VT", "America/New_York", 44.0459, -72.7107},
+	{"Virginia", "VA", "America/New_York", 37.7693, -78.1700},
+	{"Washington", "WA", "America/Los_Angeles", 47.4009, -121.4905},
+	{"West Virginia", "WV", "America/New_York", 38.4912, -80.9545},
+	{"Wisconsin", "WI", "America/Chicago", 44.2685, -89.6165},
+	{"Wyoming", "WY", "America/Denver", 42.7560, -107.3025},
+	{"Puerto Rico", "PR", "America/Puerto_Rico", 18.2208, -66.5901},
+	{"Guam", "GU", "Pacific/Guam", 13.4443, 144.7937},
+	{"U.S. Virgin Islands", "VI", "America/St_Thomas", 18.3358, -64.8963},
+	{"American Samoa", "AS", "Pacific/Pago_Pago", -14.2710, -170.1322},
+	{"Northern Mariana Islands", "MP", "Pacific/Saipan", 15.0979, 145.6739},
+}
+
+// statesByKey finds a state by its lower case name or code
+var statesByKey = func() map[string]usState {
+	states := make(map[string]usState, 2*len(usStates))
+	for _, state := range usStates {
+		states[strings.ToLower(state.name)] = state
+		states[strings.ToLower(state.code)] = state
+	}
+	return states
+}()
+
+/* [normalizeState] - Find a state by its full name or two-letter code, ignoring case and
+surrounding spaces.*/
+
+func normalizeState(state string) (usState, bool) {
+	normalized, ok := statesByKey[strings.ToLower(strings.TrimSpace(state))]
+	return normalized, ok
+}
+
+/* [matchClinicState] - Common function to match the state of a clinic against any of the state
+search phases. Known states are compared by their code, so either the name or the code matches a
+clinic stored with either of them; unknown values are compared as they are.*/
+
+func matchClinicState(stateCode string, state string, searchConditionKeys searchConditions) bool {
+	for i, stateSearchPhase := range searchConditionKeys.stateSearchPhases {
+		stateSearchCode := searchConditionKeys.stateSearchCodes[i]
+		if stateSearchCode != "" && stateCode != "" {
+			if stateSearchCode == stateCode {
+				return true
+			}
+		} else if stateSearchPhase == strings.ToLower(state) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	stateLocationsMu sync.Mutex
+	stateLocations   = map[string]*time.Location{}
+)
+
+/* [stateLocation] - Time zone of the state with the given code. Clinics in unknown states, or in
+zones missing from the system time zone database, are evaluated in UTC.*/
+
+func stateLocation(stateCode string) *time.Location {
+	stateLocationsMu.Lock()
+	defer stateLocationsMu.Unlock()
+
+	if location, ok := stateLocations[stateCode]; ok {
+		return location
+	}
+
+	location := time.UTC
+	if state, ok := normalizeState(stateCode); ok {
+		var err error
+		location, err = time.LoadLocation(state.timeZone)
+		if err != nil {
+			log.Println("Using UTC for clinics in", state.name, "while loading time zone:", err)
+			location = time.UTC
+		}
+	}
+	stateLocations[stateCode] = location
+	return location
+}
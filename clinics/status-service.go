@@ -0,0 +1,31 @@
+package clinics
+
+import "time"
+
+// clinicSourceStatus describes the health of the source of one clinic list
+type clinicSourceStatus struct {
+	CircuitBreaker breakerStatus `json:"circuit_breaker"`
+	Loader         loaderStatus  `json:"loader"`
+	LastRefreshed  *time.Time    `json:"last_refreshed,omitempty"`
+}
+
+/* [GetClinicSourcesStatus] - Report the circuit breaker state, how often the list was fetched
+and parsed, and the last successful refresh of the dental and vet clinic lists.*/
+
+func GetClinicSourcesStatus() map[string]clinicSourceStatus {
+	return map[string]clinicSourceStatus{
+		dentalClinicType: newClinicSourceStatus(dentalClinicBreaker, dentalClinicLoader, dentalClinicCache),
+		vetClinicType:    newClinicSourceStatus(vetClinicBreaker, vetClinicLoader, vetClinicCache),
+	}
+}
+
+func newClinicSourceStatus(breaker *circuitBreaker, loader *clinicListLoader, cache *clinicCache) clinicSourceStatus {
+	status := clinicSourceStatus{
+		CircuitBreaker: breaker.status(),
+		Loader:         loader.status(),
+	}
+	if lastRefreshed := cache.lastRefreshed(); !lastRefreshed.IsZero() {
+		status.LastRefreshed = &lastRefreshed
+	}
+	return status
+}
@@ -0,0 +1,185 @@
+package clinics
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of clinic names suggested by default and at most
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 100
+)
+
+// clinicSuggestion is a suggested clinic name and how many clinics have that name
+type clinicSuggestion struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+/* nameTrie finds clinic names by the start of the name or of one of its words. Every distinct
+name is added once per word, so "hea" suggests "Good Health Home".*/
+type nameTrie struct {
+	root        *trieNode
+	suggestions []clinicSuggestion
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// positions in suggestions of the names that end at this node
+	names []int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+func newNameTrie(clinics []Clinic) *nameTrie {
+	trie := &nameTrie{root: newTrieNode()}
+	positions := make(map[string]int)
+
+	for _, clinic := range clinics {
+		words := strings.Fields(strings.ToLower(clinic.ClinicName()))
+		key := strings.Join(words, " ")
+		if key == "" {
+			continue
+		}
+		if position, ok := positions[key]; ok {
+			trie.suggestions[position].Count++
+			continue
+		}
+
+		position := len(trie.suggestions)
+		positions[key] = position
+		trie.suggestions = append(trie.suggestions, clinicSuggestion{Name: clinic.ClinicName(), Count: 1})
+		for i := range words {
+			trie.insert(strings.Join(words[i:], " "), position)
+		}
+	}
+	return trie
+}
+
+func (trie *nameTrie) insert(key string, position int) {
+	node := trie.root
+	for _, r := range key {
+		child, ok := node.children[r]
+		if !ok {
+			child = newTrieNode()
+			node.children[r] = child
+		}
+		node = child
+	}
+	node.names = append(node.names, position)
+}
+
+/* [find] - Names starting with the prefix, or with a word starting with it, ignoring case. The
+most common names come first, then names in alphabetical order. It also returns how many names
+matched in total.*/
+
+func (trie *nameTrie) find(prefix string, limit int) ([]clinicSuggestion, int) {
+	node := trie.root
+	for _, r := range strings.Join(strings.Fields(strings.ToLower(prefix)), " ") {
+		node = node.children[r]
+		if node == nil {
+			return []clinicSuggestion{}, 0
+		}
+	}
+
+	found := make(map[int]bool)
+	stack := []*trieNode{node}
+	for len(stack) > 0 {
+		node, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		for _, position := range node.names {
+			found[position] = true
+		}
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+	}
+
+	suggestions := make([]clinicSuggestion, 0, len(found))
+	for position := range found {
+		suggestions = append(suggestions, trie.suggestions[position])
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return strings.ToLower(suggestions[i].Name) < strings.ToLower(suggestions[j].Name)
+	})
+
+	if len(suggestions) > limit {
+		return suggestions[:limit], len(found)
+	}
+	return suggestions, len(found)
+}
+
+/*================================================================================================
+			[SuggestClinicNames] - Suggest Clinic Names as users type
+	1) Fetch the dental, vet or both clinic lists
+	2) Find the names starting with the prefix in the name trie of the list
+	3) Return the most common names first
+================================================================================================*/
+func SuggestClinicNames(r *http.Request) ([]clinicSuggestion, searchMeta, int, error) {
+	prefix, clinicType, limit, err := validateSuggestParams(r.URL.Query())
+	if err != nil {
+		return nil, searchMeta{}, 400, err
+	}
+
+	var index *clinicIndex
+	var lastRefreshed time.Time
+	var unavailableSources []string
+	switch clinicType {
+	case dentalClinicType:
+		index, lastRefreshed, err = getCachedDentalClinicList(r.Context())
+	case vetClinicType:
+		index, lastRefreshed, err = getCachedVetClinicList(r.Context())
+	default:
+		index, lastRefreshed, unavailableSources, err = getCachedMergedClinicIndex(r.Context())
+	}
+	if err != nil {
+		return nil, searchMeta{}, 500, err
+	}
+
+	suggestions, total := index.names.find(prefix, limit)
+	meta := searchMeta{
+		lastRefreshed:      lastRefreshed,
+		total:              total,
+		hasMore:            total > len(suggestions),
+		unavailableSources: unavailableSources,
+	}
+	return suggestions, meta, 200, nil
+}
+
+/* [validateSuggestParams] - Validate the prefix, type and limit query params of the suggestions.
+The type is one of dental, vet or all, which is the default.*/
+
+func validateSuggestParams(queryParams url.Values) (string, string, int, error) {
+	prefix := strings.TrimSpace(queryParams.Get("prefix"))
+	if prefix == "" {
+		return "", "", 0, errors.New("Please provide prefix for suggestions.")
+	}
+
+	clinicType := "all"
+	if keys, ok := queryParams["type"]; ok {
+		clinicType = strings.ToLower(keys[0])
+		if clinicType != dentalClinicType && clinicType != vetClinicType && clinicType != "all" {
+			return "", "", 0, errors.New("Please provide type as dental, vet or all.")
+		}
+	}
+
+	limit := defaultSuggestLimit
+	if keys, ok := queryParams["limit"]; ok {
+		var err error
+		limit, err = strconv.Atoi(keys[0])
+		if err != nil || limit < 1 || limit > maxSuggestLimit {
+			return "", "", 0, errors.New("Please provide limit as a number between 1 and " + strconv.Itoa(maxSuggestLimit) + ".")
+		}
+	}
+	return prefix, clinicType, limit, nil
+}
@@ -0,0 +1,179 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Defaults of the client used to fetch clinic lists from remote sources
+const (
+	defaultUpstreamTimeout    = 10 * time.Second
+	defaultUpstreamMaxRetries = 2
+	upstreamBaseBackoff       = 200 * time.Millisecond
+)
+
+// UpstreamError is returned when a clinic list could not be fetched from a remote source
+type UpstreamError struct {
+	URL        string
+	StatusCode int  // status returned by the upstream, 0 if no response was received
+	Timeout    bool // the upstream did not answer in time
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Timeout {
+		return "Clinic data provider did not respond in time."
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("Clinic data provider responded with status %d.", e.StatusCode)
+	}
+	return "Clinic data provider is unreachable."
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+/* [HTTPStatusCode] - Status code to answer with when the upstream fails: 504 for timeouts and
+502 for everything else.*/
+
+func (e *UpstreamError) HTTPStatusCode() int {
+	if e.Timeout {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// upstreamClient fetches remote files with a timeout per attempt and retries with backoff
+type upstreamClient struct {
+	httpClient *http.Client
+	timeout    time.Duration
+	maxRetries int
+}
+
+var defaultUpstreamClient = newUpstreamClient(defaultUpstreamTimeout, defaultUpstreamMaxRetries)
+
+func newUpstreamClient(timeout time.Duration, maxRetries int) *upstreamClient {
+	return &upstreamClient{
+		httpClient: &http.Client{},
+		timeout:    timeout,
+		maxRetries: maxRetries,
+	}
+}
+
+/* [ConfigureUpstreamClient] - Set the timeout of every attempt to fetch a remote clinic list and
+how many times a failed attempt is retried. A zero timeout or negative maxRetries keep the
+defaults. It must be called before the server starts.*/
+
+func ConfigureUpstreamClient(timeout time.Duration, maxRetries int) {
+	if timeout > 0 {
+		defaultUpstreamClient.timeout = timeout
+	}
+	if maxRetries >= 0 {
+		defaultUpstreamClient.maxRetries = maxRetries
+	}
+}
+
+// upstreamResponse is a successful (200 or 304) response of the upstream
+type upstreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+/* [get] - Fetch the url with the given request headers. Network errors and 5xx responses are
+retried with exponential backoff until maxRetries is reached or ctx is done. Both 200 and 304
+responses are successful. When the caller cancels ctx its error is returned as it is, as the
+upstream is not to blame.*/
+
+func (c *upstreamClient) get(ctx context.Context, url string, header http.Header) (*upstreamResponse, error) {
+	var lastErr *UpstreamError
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := upstreamBaseBackoff * time.Duration(1<<uint(attempt-1))
+			log.Printf("Retrying %s in %v after: %v", url, backoff, lastErr.Err)
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.Canceled) {
+					return nil, ctx.Err()
+				}
+				return nil, newUpstreamError(url, 0, ctx.Err())
+			}
+		}
+
+		response, upstreamErr := c.getOnce(ctx, url, header)
+		if upstreamErr == nil {
+			return response, nil
+		}
+		lastErr = upstreamErr
+
+		// client errors and a cancelled caller will not get better by retrying
+		if (upstreamErr.StatusCode != 0 && upstreamErr.StatusCode < 500) || ctx.Err() != nil {
+			break
+		}
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	}
+	return nil, lastErr
+}
+
+func (c *upstreamClient) getOnce(ctx context.Context, url string, header http.Header) (*upstreamResponse, *UpstreamError) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(attemptCtx, "GET", url, nil)
+	if err != nil {
+		return nil, newUpstreamError(url, 0, err)
+	}
+	for key := range header {
+		request.Header.Set(key, header.Get(key))
+	}
+	request.Header.Set("Accept", "application/json")
+
+	// send HTTP request using request object
+	res, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, newUpstreamError(url, 0, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotModified {
+		// drain the body so the connection can be reused
+		ioutil.ReadAll(res.Body)
+		return nil, newUpstreamError(url, res.StatusCode, fmt.Errorf("unexpected status %s", res.Status))
+	}
+
+	// read response body
+	dataByte, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, newUpstreamError(url, 0, err)
+	}
+	return &upstreamResponse{StatusCode: res.StatusCode, Header: res.Header, Body: dataByte}, nil
+}
+
+func newUpstreamError(url string, statusCode int, err error) *UpstreamError {
+	return &UpstreamError{
+		URL:        url,
+		StatusCode: statusCode,
+		Timeout:    isTimeout(err),
+		Err:        err,
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
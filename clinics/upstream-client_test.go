@@ -0,0 +1,55 @@
+package clinics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUpstreamClientGetCancelledByCaller(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := newUpstreamClient(time.Second, 2).get(ctx, server.URL, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("get() error = %v, want context.Canceled", err)
+	}
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		t.Fatalf("get() error = %v, want the error of the caller instead of an UpstreamError", err)
+	}
+}
+
+func TestUpstreamClientGetServerErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newUpstreamClient(time.Second, 1).get(context.Background(), server.URL, nil)
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("get() error = %v, want an UpstreamError with status 503", err)
+	}
+	if upstreamErr.HTTPStatusCode() != http.StatusBadGateway {
+		t.Errorf("HTTPStatusCode() = %d, want %d", upstreamErr.HTTPStatusCode(), http.StatusBadGateway)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
@@ -1,214 +1,104 @@
 package clinics
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"io/ioutil"
 	"net/http"
-	"strings"
 	"time"
 )
 
 type vetClinicInfo struct {
-	Name        string  `json:"clinicName"`
-	State       string  `json:"stateCode"`
-	Availablity timings `json:"opening"`
+	Name                string       `json:"clinicName"`
+	State               string       `json:"stateCode"`
+	StateName           string       `json:"stateName,omitempty"`
+	Availablity         openingHours `json:"opening"`
+	WeeklyHours         weeklyHours  `json:"weeklyHours,omitempty"`
+	Closures            []string     `json:"closures,omitempty"`
+	Score               float64      `json:"score,omitempty"`
+	Lat                 *float64     `json:"lat,omitempty"`
+	Lng                 *float64     `json:"lng,omitempty"`
+	ApproximateLocation bool         `json:"approximateLocation,omitempty"`
+	DistanceKm          *float64     `json:"distanceKm,omitempty"`
+
+	schedule clinicSchedule
+}
+
+var vetClinicLoader = newClinicListLoader(vetClinicType, parseVetClinicList)
+
+// stateCode is the state code of the clinic if it is a known state
+func (clinic vetClinicInfo) stateCode() string {
+	if clinic.StateName == "" {
+		return ""
+	}
+	return clinic.State
 }
 
 /*================================================================================================
 			[SearchVetClinics] - Search Vet Clinics
 	1) Fetch all clinics if no search condition is provided
 	2) Fetch clinincs which satisfy search conditions
+	3) Sort the clinics and return the requested page
 ================================================================================================*/
-func SearchVetClinics(r *http.Request) ([]vetClinicInfo, int, error) {
-	var filteredClinicData = []vetClinicInfo{}
-
+func SearchVetClinics(r *http.Request) ([]Clinic, searchMeta, int, error) {
 	queryParams := r.URL.Query()
 	searchConditionKeys, searchOperator, onlyTimeConditionExists, err := validateQueryParams(queryParams)
 	if err != nil {
-		return nil, 400, err
+		return nil, searchMeta{}, 400, err
 	}
 
-	vetClinicData, err := getVetClinicList()
+	vetClinicIndex, lastRefreshed, err := getCachedVetClinicList(r.Context())
 	if err != nil {
-		return nil, 500, err
+		return nil, searchMeta{}, 500, err
 	}
+	meta := searchMeta{lastRefreshed: lastRefreshed}
 
-	// return all clinics if there is no search condition
-	if len(r.URL.Query()) == 0 {
-		return vetClinicData, 200, nil
-	}
-
-	//conditional functional call, based on search operator
-	if searchOperator == "and" {
-		filteredClinicData = searchVetClinicsBasedOnAndCondition(vetClinicData, searchConditionKeys, onlyTimeConditionExists)
-	} else {
-		filteredClinicData = searchVetClinicsBasedOnOrCondition(vetClinicData, searchConditionKeys)
-	}
-	return filteredClinicData, 200, nil
+	result := searchClinicList(vetClinicIndex, searchConditionKeys, searchOperator, onlyTimeConditionExists, &meta)
+	return result, meta, 200, nil
 }
 
-/* [getVetClinicList] - Get list of all vet clinics from a url.*/
+/* [getCachedVetClinicList] - Get the indexed list of all vet clinics from the cache, along with the time
+the list was loaded.*/
 
-func getVetClinicList() ([]vetClinicInfo, error) {
-	request, err := http.NewRequest("GET", "https://storage.googleapis.com/scratchpay-code-challenge/vet-clinics.json", nil)
-	request.Header.Set("Content-Type", "application/json")
+func getCachedVetClinicList(ctx context.Context) (*clinicIndex, time.Time, error) {
+	data, lastRefreshed, err := vetClinicCache.get(ctx)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
+	return data.(*clinicIndex), lastRefreshed, nil
+}
 
-	// send HTTP request using request object
-	res, err := http.DefaultClient.Do(request)
-	if err != nil {
-		fmt.Println(err)
-		err := errors.New("There is some issue.")
-		return nil, err
-	}
+/* [getVetClinicList] - Get list of all vet clinics from its configured source, and whether it was
+parsed again instead of reused.*/
 
-	// read response body
-	dataByte, err := ioutil.ReadAll(res.Body)
+func getVetClinicList(ctx context.Context) ([]vetClinicInfo, bool, error) {
+	data, parsed, err := vetClinicLoader.load(ctx, vetClinicSource, vetClinicBreaker)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	res.Body.Close()
+	return data.([]vetClinicInfo), parsed, nil
+}
 
+/* [parseVetClinicList] - Convert the raw clinic list into vet clinics.*/
+
+func parseVetClinicList(dataByte []byte) (interface{}, error) {
 	// convert byte array into json format
 	responseData := make([]vetClinicInfo, 0)
-
-	err = json.Unmarshal(dataByte, &responseData)
+	err := json.Unmarshal(dataByte, &responseData)
 	if err != nil {
 		return nil, err
 	}
 
-	return responseData, nil
-}
-
-/* [searchClinicsBasedOnAndCondition] - This function is used to filter out clinics based on
-search conditions and search operator = AND.*/
-
-func searchVetClinicsBasedOnAndCondition(clinicsData []vetClinicInfo, searchConditionKeys searchConditions, onlyTimeConditionExists bool) []vetClinicInfo {
-	filteredData := make([]vetClinicInfo, 0)
-
-	for i := range clinicsData {
-
-		// Search condition check for clininc name
-		isSearchConditionMatched := false
-
-		clinicNameLowerCase := strings.ToLower(clinicsData[i].Name)
-		if searchConditionKeys.clinicNameSearchPhase != "" {
-			if searchConditionKeys.clinicNameSearchPhase == clinicNameLowerCase {
-				isSearchConditionMatched = true
-			} else {
-				clinicNameSubStrings := strings.Split(clinicNameLowerCase, " ")
-				for j := range clinicNameSubStrings {
-					if searchConditionKeys.clinicNameSearchPhase == clinicNameSubStrings[j] {
-						isSearchConditionMatched = true
-					}
-				}
-			}
-		}
-
-		// Search condition check for state
-		if searchConditionKeys.stateSearchPhase != "" {
-			if isSearchConditionMatched || searchConditionKeys.clinicNameSearchPhase == "" {
-				if searchConditionKeys.stateSearchPhase == strings.ToLower(clinicsData[i].State) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			}
-		}
-
-		// Search condition check for opening and closing time
-
-		// convert time availability data from string to time format
-		availableFrom, _ := time.Parse("15:04", clinicsData[i].Availablity.From)
-		availableTo, _ := time.Parse("15:04", clinicsData[i].Availablity.To)
-
-		if onlyTimeConditionExists || isSearchConditionMatched {
-			if searchConditionKeys.timeFromStr != "" && searchConditionKeys.timeToStr != "" {
-				if availableFrom.After(searchConditionKeys.timeFrom) &&
-					availableTo.Before(searchConditionKeys.timeTo) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			} else if searchConditionKeys.timeFromStr != "" {
-				if availableFrom.After(searchConditionKeys.timeFrom) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			} else if searchConditionKeys.timeToStr != "" {
-				if availableTo.Before(searchConditionKeys.timeTo) {
-					isSearchConditionMatched = true
-				} else {
-					isSearchConditionMatched = false
-				}
-			}
-		}
-
-		// Search condition check for time
-
-		if isSearchConditionMatched {
-			filteredData = append(filteredData, clinicsData[i])
+	// vet clinics come with the state code, add the name
+	for i := range responseData {
+		if state, ok := normalizeState(responseData[i].State); ok {
+			responseData[i].State = state.code
+			responseData[i].StateName = state.name
 		}
+		responseData[i].schedule = newClinicSchedule(vetClinicType, responseData[i].Name, responseData[i].State,
+			responseData[i].Availablity, responseData[i].WeeklyHours, responseData[i].Closures)
+		responseData[i].Lat, responseData[i].Lng, responseData[i].ApproximateLocation = locateClinic(vetClinicType,
+			responseData[i].Name, responseData[i].Lat, responseData[i].Lng, responseData[i].stateCode())
 	}
-	return filteredData
-}
 
-/* [searchVetClinicsBasedOnOrCondition] - This function is used to filter out VET clinics based on
-search conditions and search operator = OR.*/
-func searchVetClinicsBasedOnOrCondition(clinicsData []vetClinicInfo, searchConditionKeys searchConditions) []vetClinicInfo {
-	filteredData := make([]vetClinicInfo, 0)
-
-	for i := range clinicsData {
-
-		// Search condition check for clininc name
-		isSearchConditionMatched := false
-
-		clinicNameLowerCase := strings.ToLower(clinicsData[i].Name)
-		if searchConditionKeys.clinicNameSearchPhase == clinicNameLowerCase {
-			isSearchConditionMatched = true
-		} else {
-			clinicNameSubStrings := strings.Split(clinicNameLowerCase, " ")
-			for j := range clinicNameSubStrings {
-				if searchConditionKeys.clinicNameSearchPhase == clinicNameSubStrings[j] {
-					isSearchConditionMatched = true
-				}
-			}
-		}
-
-		// Search condition check for state
-		if searchConditionKeys.stateSearchPhase == strings.ToLower(clinicsData[i].State) {
-			isSearchConditionMatched = true
-		}
-
-		// Search condition check for time
-
-		// convert time availability data from string to time format
-		availableFrom, _ := time.Parse("15:04", clinicsData[i].Availablity.From)
-		availableTo, _ := time.Parse("15:04", clinicsData[i].Availablity.To)
-
-		if searchConditionKeys.timeFromStr != "" && searchConditionKeys.timeToStr != "" {
-			if availableFrom.After(searchConditionKeys.timeFrom) &&
-				availableTo.Before(searchConditionKeys.timeTo) {
-				isSearchConditionMatched = true
-			}
-		} else if searchConditionKeys.timeFromStr != "" {
-			if availableFrom.After(searchConditionKeys.timeFrom) {
-				isSearchConditionMatched = true
-			}
-		} else if searchConditionKeys.timeToStr != "" {
-			if availableTo.Before(searchConditionKeys.timeTo) {
-				isSearchConditionMatched = true
-			}
-		}
-
-		if isSearchConditionMatched {
-			filteredData = append(filteredData, clinicsData[i])
-		}
-	}
-	return filteredData
+	return responseData, nil
 }
@@ -1,10 +1,13 @@
 package main
 
 import (
+	clinicsService "coding-challenge/clinics"
 	"coding-challenge/routers"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -25,6 +28,25 @@ func main() {
 		log.Println("Server started at port ", port)
 	}
 
+	/* Clinic lists are loaded from DENTAL_CLINICS_SOURCE and VET_CLINICS_SOURCE, each being an
+	http(s) url, a JSON file path or "memory" for sample data. Remote files are used by default */
+	sourceErr := clinicsService.ConfigureClinicSources(os.Getenv("DENTAL_CLINICS_SOURCE"), os.Getenv("VET_CLINICS_SOURCE"))
+	if sourceErr != nil {
+		log.Fatalln("While configuring clinic sources: ", sourceErr)
+	}
+
+	/* Remote clinic lists are fetched with UPSTREAM_TIMEOUT per attempt and failed attempts
+	are retried UPSTREAM_MAX_RETRIES times */
+	clinicsService.ConfigureUpstreamClient(getEnvDuration("UPSTREAM_TIMEOUT"), getEnvInt("UPSTREAM_MAX_RETRIES", -1))
+
+	/* The source of a clinic list is not called for CIRCUIT_BREAKER_OPEN_TIMEOUT after it
+	failed CIRCUIT_BREAKER_THRESHOLD times in a row */
+	clinicsService.ConfigureCircuitBreakers(getEnvInt("CIRCUIT_BREAKER_THRESHOLD", 0), getEnvDuration("CIRCUIT_BREAKER_OPEN_TIMEOUT"))
+
+	/* Clinic lists are cached and refreshed in the background. The interval can be set
+	with CLINIC_CACHE_REFRESH_INTERVAL using Go duration format, e.g. "10m" */
+	clinicsService.StartClinicCacheRefresh(getEnvDuration("CLINIC_CACHE_REFRESH_INTERVAL"))
+
 	// Initalize all the routes and start the server
 	router := routers.InitRoutes()
 	httpError := http.ListenAndServe(":"+port, router)
@@ -32,3 +54,31 @@ func main() {
 		log.Println("While serving HTTP: ", httpError)
 	}
 }
+
+// getEnvDuration reads a Go duration from env, zero if it is not set or invalid
+func getEnvDuration(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Println("Invalid "+name+", using default: ", err)
+		return 0
+	}
+	return duration
+}
+
+// getEnvInt reads an integer from env, defaultValue if it is not set or invalid
+func getEnvInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Println("Invalid "+name+", using default: ", err)
+		return defaultValue
+	}
+	return number
+}
@@ -2,10 +2,42 @@ package middleware
 
 import "net/http"
 
-//SetMiddlewareJSON set content type in all response
+//SetMiddlewareJSON set content type in all response, unless the handler sets another content type
 func SetMiddlewareJSON(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		next(w, r)
+		next(&jsonResponseWriter{ResponseWriter: w}, r)
+	}
+}
+
+// jsonResponseWriter defaults the content type to JSON when the response is written
+type jsonResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *jsonResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *jsonResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends the response written so far, so handlers can stream responses
+func (w *jsonResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
 	}
 }